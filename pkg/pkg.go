@@ -23,10 +23,12 @@ import (
 	"sync"
 	"time"
 
-	lib "github.com/SENERGY-Platform/smart-service-module-worker-lib"
+	"github.com/SENERGY-Platform/device-repository/lib/client"
+	libpkg "github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/camunda"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/configuration"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/middleware"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/smartservicerepository"
 	"github.com/SENERGY-Platform/smart-service-module-worker-process/pkg/processdeployment"
@@ -70,7 +72,50 @@ func Start(ctx context.Context, wg *sync.WaitGroup, config processdeployment.Con
 		smartServiceRepo.RunHealthCheck(moduleQuery, healthCheck)                  //initial check
 		return handler, nil
 	}
-	return lib.Start(ctx, wg, libConfig, handlerFactory)
+	err := startWorker(ctx, wg, libConfig, handlerFactory)
+	if err != nil {
+		return err
+	}
+	if config.DryRunWorkerTopic != "" {
+		err = startDryRunWorker(ctx, wg, config, libConfig)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startDryRunWorker starts a second worker, that handles every task of the config.DryRunWorkerTopic as dry-run
+func startDryRunWorker(ctx context.Context, wg *sync.WaitGroup, config processdeployment.Config, libConfig configuration.Config) error {
+	libConfig.CamundaWorkerTopic = config.DryRunWorkerTopic
+	return startWorker(ctx, wg, libConfig, func(auth *auth.Auth, smartServiceRepo *smartservicerepository.SmartServiceRepository) (camunda.Handler, error) {
+		return processdeployment.NewDryRun(ctx, wg, config, libConfig, auth, smartServiceRepo)
+	})
+}
+
+// startWorker starts the camunda worker like lib.Start, but does not handle errors with scheduled camunda retries as instance errors
+func startWorker(ctx context.Context, wg *sync.WaitGroup, libConfig configuration.Config, handlerFactory libpkg.HandlerFactory) error {
+	auth := auth.New(libConfig)
+	smartServiceRepo := smartservicerepository.New(libConfig, auth)
+	handler, err := handlerFactory(auth, smartServiceRepo)
+	if err != nil {
+		return err
+	}
+	m := middleware.New(libConfig, handler, smartServiceRepo, auth, client.NewClient(libConfig.DeviceRepositoryUrl, nil))
+	camunda.Start(ctx, wg, libConfig, retryAwareRepo{SmartServiceRepository: smartServiceRepo}, m)
+	return nil
+}
+
+// retryAwareRepo prevents the camunda worker from stopping process instances of tasks with a scheduled retry or a thrown bpmn error
+type retryAwareRepo struct {
+	*smartservicerepository.SmartServiceRepository
+}
+
+func (this retryAwareRepo) SendWorkerError(task model.CamundaExternalTask, err error) error {
+	if processdeployment.IsRetryScheduled(err) || processdeployment.IsBpmnErrorThrown(err) {
+		return err
+	}
+	return this.SmartServiceRepository.SendWorkerError(task, err)
 }
 
 func getDeploymentId(moduleData map[string]interface{}) (isFogDeployment bool, fogHubId string, deploymentId string, err error) {
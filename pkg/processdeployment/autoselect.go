@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deviceselectionmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+	"github.com/SENERGY-Platform/smart-service-module-worker-process/pkg/processdeployment/idmodifier"
+)
+
+const (
+	AutoSelectStrategyUnique          = "unique"
+	AutoSelectStrategyPreferredDevice = "preferred_device"
+	AutoSelectStrategyFogHub          = "fog_hub"
+)
+
+func (this *ProcessDeployment) getAutoSelect(task model.CamundaExternalTask) (bool, error) {
+	result, _, err := getBoolVariable(task, this.config.WorkerParamPrefix+"auto_select")
+	return result, err
+}
+
+// prepareDeployment requests the prepared deployment of the process model; selection options are only requested for auto_select
+func (this *ProcessDeployment) prepareDeployment(token auth.Token, task model.CamundaExternalTask, modelId string) (deployment deploymentmodel.Deployment, err error) {
+	autoSelect, err := this.getAutoSelect(task)
+	if err != nil {
+		return deployment, withErrorCode(ErrCodeInvalidInput, err)
+	}
+	return this.PrepareRequest(token, modelId, autoSelect)
+}
+
+// usesAutoSelection returns true if auto_select is set and the element has no selection, selection_ref, group-selection or default-selection variable
+func (this *ProcessDeployment) usesAutoSelection(task model.CamundaExternalTask, element *deploymentmodel.Element) (bool, error) {
+	if getElementSelection(element) == nil {
+		return false, nil
+	}
+	autoSelect, err := this.getAutoSelect(task)
+	if err != nil || !autoSelect {
+		return false, err
+	}
+	names := []string{
+		this.config.WorkerParamPrefix + element.BpmnId + ".selection",
+		this.config.WorkerParamPrefix + element.BpmnId + ".selection_ref",
+		this.config.WorkerParamPrefix + "default.selection",
+	}
+	if element.Group != nil && *element.Group != "" {
+		names = append(names, this.config.WorkerParamPrefix+"group."+*element.Group+".selection")
+	}
+	for _, name := range names {
+		if _, exists := task.Variables[name]; exists {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+type selectionCandidate struct {
+	DeviceId  string
+	ServiceId string
+	Paths     []deviceselectionmodel.PathOption
+}
+
+// autoSelect selects a device and service from the selection options of every element that uses auto selection.
+// transient errors (e.g. of the fog hub lookup) are returned as err instead of element errors, to keep them retryable.
+func (this *ProcessDeployment) autoSelect(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment) (result []ElementError, err error) {
+	var filter func(candidates []selectionCandidate) ([]selectionCandidate, error)
+	for i := range deployment.Elements {
+		element := &deployment.Elements[i]
+		auto, err := this.usesAutoSelection(task, element)
+		if err != nil {
+			return []ElementError{{BpmnId: element.BpmnId, Name: element.Name, Message: err.Error()}}, nil
+		}
+		if !auto {
+			continue
+		}
+		if filter == nil {
+			filter, err = this.getAutoSelectFilter(task)
+			if err != nil {
+				return []ElementError{{BpmnId: element.BpmnId, Name: element.Name, Message: err.Error()}}, nil
+			}
+		}
+		optional, err := this.isOptionalElement(task, element)
+		if err != nil {
+			result = append(result, ElementError{BpmnId: element.BpmnId, Name: element.Name, Message: err.Error()})
+			continue
+		}
+		err = autoSelectElement(element, filter, optional)
+		if err != nil && IsRetryable(err) {
+			return nil, err
+		}
+		if err != nil {
+			result = append(result, ElementError{BpmnId: element.BpmnId, Name: element.Name, Message: err.Error()})
+		}
+	}
+	return result, nil
+}
+
+// autoSelectElement selects the only candidate of the element; optional elements without candidates stay unselected
+func autoSelectElement(element *deploymentmodel.Element, filter func(candidates []selectionCandidate) ([]selectionCandidate, error), optional bool) error {
+	selection := getElementSelection(element)
+	candidates := []selectionCandidate{}
+	for _, option := range selection.SelectionOptions {
+		if option.Device == nil {
+			continue
+		}
+		for _, service := range option.Services {
+			candidates = append(candidates, selectionCandidate{
+				DeviceId:  option.Device.Id,
+				ServiceId: service.Id,
+				Paths:     option.ServicePathOptions[service.Id],
+			})
+		}
+	}
+	filtered, err := filter(candidates)
+	if err != nil {
+		return err
+	}
+	if len(filtered) > 0 {
+		candidates = filtered
+	}
+	if len(candidates) == 0 && optional {
+		return nil
+	}
+	if len(candidates) != 1 {
+		return fmt.Errorf("unable to auto select %v: expected one selection option, found %v", element.BpmnId, len(candidates))
+	}
+	candidate := candidates[0]
+	if len(candidate.Paths) > 1 {
+		return fmt.Errorf("unable to auto select %v: expected one path option for service %v, found %v", element.BpmnId, candidate.ServiceId, len(candidate.Paths))
+	}
+	selection.SelectedDeviceId = &candidate.DeviceId
+	selection.SelectedServiceId = &candidate.ServiceId
+	if len(candidate.Paths) == 1 {
+		selection.SelectedPath = &candidate.Paths[0]
+	}
+	return nil
+}
+
+// getAutoSelectFilter returns a filter for the configured auto_select_strategy.
+// if a filter returns no candidate, all candidates are used.
+func (this *ProcessDeployment) getAutoSelectFilter(task model.CamundaExternalTask) (func(candidates []selectionCandidate) ([]selectionCandidate, error), error) {
+	strategyVariable := this.config.WorkerParamPrefix + "auto_select_strategy"
+	strategy, _, err := getStringVariable(task, strategyVariable)
+	if err != nil {
+		return nil, err
+	}
+	switch strategy {
+	case "", AutoSelectStrategyUnique:
+		return func(candidates []selectionCandidate) ([]selectionCandidate, error) {
+			return nil, nil
+		}, nil
+	case AutoSelectStrategyPreferredDevice:
+		preferred := []string{}
+		preferredVariable := this.config.WorkerParamPrefix + "auto_select_preferred_devices"
+		exists, err := getJsonVariable(task, preferredVariable, &preferred)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("missing %v for %v %v", preferredVariable, strategyVariable, strategy)
+		}
+		return func(candidates []selectionCandidate) ([]selectionCandidate, error) {
+			return filterCandidateDevices(candidates, preferred), nil
+		}, nil
+	case AutoSelectStrategyFogHub:
+		hubVariable := this.config.WorkerParamPrefix + "auto_select_fog_hub_id"
+		hubId, _, err := getStringVariable(task, hubVariable)
+		if err != nil {
+			return nil, err
+		}
+		if hubId == "" {
+			hubId, err = this.getFogHubId(task)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if hubId == "" {
+			return nil, fmt.Errorf("missing %v or %vfog_hub_id for %v %v", hubVariable, this.config.WorkerParamPrefix, strategyVariable, strategy)
+		}
+		var hubDevices []string
+		return func(candidates []selectionCandidate) ([]selectionCandidate, error) {
+			if hubDevices == nil {
+				hubDevices, err = this.getFogHubDevices(task, hubId)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return filterCandidateDevices(candidates, hubDevices), nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown %v %v", strategyVariable, strategy)
+	}
+}
+
+// filterCandidateDevices returns the candidates with one of the devices; id modifiers are ignored
+func filterCandidateDevices(candidates []selectionCandidate, deviceIds []string) (result []selectionCandidate) {
+	pureDeviceIds := pureIds(deviceIds)
+	for _, candidate := range candidates {
+		if slices.Contains(pureDeviceIds, idmodifier.PureId(candidate.DeviceId)) {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+func (this *ProcessDeployment) getFogHubDevices(task model.CamundaExternalTask, hubId string) ([]string, error) {
+	token, err := this.getInstanceUserToken(task)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := this.GetFogNetworks(token)
+	if err != nil {
+		return nil, err
+	}
+	for _, network := range networks {
+		if network.Id == hubId {
+			return append([]string{}, this.hubDeviceIds(network)...), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown fog hub %v", hubId)
+}
+
+// getAutoSelections returns the selections of all auto selected elements as json map of BpmnId to model.IotOption
+func (this *ProcessDeployment) getAutoSelections(task model.CamundaExternalTask, deployment deploymentmodel.Deployment) (result string, exists bool, err error) {
+	selections := map[string]model.IotOption{}
+	for _, element := range deployment.Elements {
+		auto, err := this.usesAutoSelection(task, &element)
+		if err != nil {
+			return "", false, err
+		}
+		selection := getElementSelection(&element)
+		if !auto || selection.SelectedDeviceId == nil {
+			continue
+		}
+		option := model.IotOption{DeviceSelection: &model.DeviceSelection{
+			DeviceId:  *selection.SelectedDeviceId,
+			ServiceId: selection.SelectedServiceId,
+		}}
+		if selection.SelectedPath != nil {
+			option.DeviceSelection.Path = &selection.SelectedPath.Path
+			if selection.SelectedPath.CharacteristicId != "" {
+				option.DeviceSelection.CharacteristicId = &selection.SelectedPath.CharacteristicId
+			}
+		}
+		selections[element.BpmnId] = option
+	}
+	if len(selections) == 0 {
+		return "", false, nil
+	}
+	temp, err := json.Marshal(selections)
+	return string(temp), true, err
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+	"github.com/dop251/goja"
+)
+
+var jsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// setConditionalEventConfig sets script, value_variable and qos of a conditional event and checks the script syntax
+func (this *ProcessDeployment) setConditionalEventConfig(task model.CamundaExternalTask, element *deploymentmodel.Element) error {
+	if element.ConditionalEvent == nil {
+		return nil
+	}
+	prefix := this.config.WorkerParamPrefix + element.BpmnId + ".conditional."
+
+	script, exists, err := getStringVariable(task, prefix+"script")
+	if err != nil {
+		return err
+	}
+	if exists {
+		element.ConditionalEvent.Script = script
+	}
+
+	valueVariable, exists, err := getStringVariable(task, prefix+"value_variable")
+	if err != nil {
+		return err
+	}
+	if exists {
+		if !jsIdentifierPattern.MatchString(valueVariable) {
+			return fmt.Errorf("unexpected value in %v: %v is not a valid variable name", prefix+"value_variable", valueVariable)
+		}
+		element.ConditionalEvent.ValueVariable = valueVariable
+	}
+
+	qos, exists, err := getVariable(task, prefix+"qos")
+	if err != nil {
+		return err
+	}
+	if exists && qos != nil {
+		value, err := toInt(qos)
+		if err != nil {
+			return fmt.Errorf("unexpected value in %v: %w", prefix+"qos", err)
+		}
+		if value < 0 || value > 2 {
+			return fmt.Errorf("unexpected value in %v: qos must be 0, 1 or 2", prefix+"qos")
+		}
+		element.ConditionalEvent.Qos = int(value)
+	}
+
+	if element.ConditionalEvent.Script != "" {
+		_, err = goja.Compile(element.BpmnId, element.ConditionalEvent.Script, false)
+		if err != nil {
+			return fmt.Errorf("invalid conditional-event script: %w", err)
+		}
+	}
+	return nil
+}
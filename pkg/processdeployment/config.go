@@ -17,15 +17,27 @@
 package processdeployment
 
 type Config struct {
-	ProcessDeploymentUrl         string `json:"process_deployment_url"`
-	FogProcessDeploymentUrl      string `json:"fog_process_deployment_url"`
-	FogProcessSyncUrl            string `json:"fog_process_sync_url"`
-	DeviceRepositoryUrl          string `json:"device_repository_url"`
-	AllowMsgEventsInFogProcesses bool   `json:"allow_msg_events_in_fog_processes"`
-	AllowImportsInFogProcesses   bool   `json:"allow_imports_in_fog_processes"`
-	ProcessDeploymentSource      string `json:"process_deployment_source"`
-	WorkerParamPrefix            string `json:"worker_param_prefix"`
-	InitTopics                   bool   `json:"init_topics"`
+	ProcessDeploymentUrl         string            `json:"process_deployment_url"`
+	FogProcessDeploymentUrl      string            `json:"fog_process_deployment_url"`
+	FogProcessSyncUrl            string            `json:"fog_process_sync_url"`
+	DeviceRepositoryUrl          string            `json:"device_repository_url"`
+	AllowMsgEventsInFogProcesses bool              `json:"allow_msg_events_in_fog_processes"`
+	AllowImportsInFogProcesses   bool              `json:"allow_imports_in_fog_processes"`
+	ProcessDeploymentSource      string            `json:"process_deployment_source"`
+	WorkerParamPrefix            string            `json:"worker_param_prefix"`
+	InitTopics                   bool              `json:"init_topics"`
+	DryRunWorkerTopic            string            `json:"dry_run_worker_topic"`
+	DoneEventTimeout             string            `json:"done_event_timeout"`
+	DoneEventBackoff             string            `json:"done_event_backoff"`
+	DoneEventMaxBackoff          string            `json:"done_event_max_backoff"`
+	EventOutboxFile              string            `json:"event_outbox_file"`
+	CamundaTaskRetries           int64             `json:"camunda_task_retries"`
+	CamundaTaskRetryTimeout      string            `json:"camunda_task_retry_timeout"`
+	ValidateSelections           bool              `json:"validate_selections"`
+	FlowRepoUrl                  string            `json:"flow_repo_url"`
+	EventFlowIds                 map[string]string `json:"event_flow_ids"`
+	ElementEventFlowIds          map[string]string `json:"element_event_flow_ids"`
+	DefaultEventFlowId           string            `json:"default_event_flow_id"`
 
 	HealthCheckInterval string `json:"health_check_interval"`
 }
@@ -17,23 +17,69 @@
 package processdeployment
 
 import (
+	"errors"
+	"net/http"
 	"time"
 
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/camunda"
-	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/configuration"
 )
 
-func triggerDoneEvent(libConfig configuration.Config, resourceId string) {
-	go func() {
-		time.Sleep(5 * time.Second)
-		eventId := deploymentIdToEventId(resourceId)
-		err := camunda.SendEventTrigger(libConfig, eventId, nil)
+// triggerDoneEvent adds the deployment to the event outbox, which sends the done event once the deployment is ready.
+// if the deployment is not ready within config.DoneEventTimeout, the failed event is sent instead.
+func (this *ProcessDeployment) triggerDoneEvent(userId string, deploymentId string, isFogDeployment bool, hubId string) {
+	err := this.outbox.Add(outboxEntry{
+		DeploymentId:    deploymentId,
+		UserId:          userId,
+		IsFogDeployment: isFogDeployment,
+		HubId:           hubId,
+		ReadyDeadline:   time.Now().Add(this.doneEventTimeout),
+	})
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to persist done event", "deploymentId", deploymentId, "error", err)
+	}
+}
+
+func (this *ProcessDeployment) isDeploymentReady(entry outboxEntry) error {
+	return this.checkDeploymentReady(entry.UserId, entry.DeploymentId, entry.IsFogDeployment, entry.HubId)
+}
+
+func (this *ProcessDeployment) sendEventTrigger(eventId string) error {
+	return camunda.SendEventTrigger(this.libConfig, eventId, nil)
+}
+
+func (this *ProcessDeployment) checkDeploymentReady(userId string, deploymentId string, isFogDeployment bool, hubId string) error {
+	token, err := this.auth.ExchangeUserToken(userId)
+	if err != nil {
+		return err
+	}
+	if isFogDeployment {
+		health, err := this.CheckFogDeployment(token, hubId, deploymentId)
 		if err != nil {
-			libConfig.GetLogger().Error("unable to send event trigger", "error", err)
+			return err
 		}
-	}()
+		return health
+	}
+	code, err := this.CheckDeployment(token, deploymentId)
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK {
+		return errors.New("unexpected deployment check status-code " + http.StatusText(code))
+	}
+	return nil
 }
 
 func deploymentIdToEventId(id string) string {
 	return "deployment_done_" + id
 }
+
+// deploymentIdToFailedEventId returns the event that is sent instead of the done event, if the deployment is not ready before done_event_timeout
+func deploymentIdToFailedEventId(id string) string {
+	return "deployment_failed_" + id
+}
+
+// processInstanceIdToTaskFailedEventId returns the event that is sent, if the task of the process instance fails.
+// the prefix differs from deploymentIdToFailedEventId to prevent collisions of deployment ids and process instance ids.
+func processInstanceIdToTaskFailedEventId(id string) string {
+	return "deployment_task_failed_" + id
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// DryRun prepares the deployment like Do but does not deploy it.
+// the rendered deployment, the fog placement decision and all found validation errors are returned as outputs.
+// validation errors do not fail the task; only errors that prevent the rendering of the deployment and retryable errors are returned as error.
+func (this *ProcessDeployment) DryRun(token auth.Token, task model.CamundaExternalTask, modelId string) (outputs map[string]interface{}, err error) {
+	deployment, err := this.prepareDeployment(token, task, modelId)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to prepare process deployment", "error", err)
+		return outputs, err
+	}
+
+	validationErrors := []string{}
+	isFogDeployment := false
+	hubId := ""
+
+	err = this.UseVariables(task, &deployment)
+	if err != nil && IsRetryable(err) {
+		return outputs, err
+	}
+	if err != nil {
+		validationErrors = append(validationErrors, errorMessages(err)...)
+	} else {
+		err = this.validateSelections(token, deployment)
+		if err != nil && IsRetryable(err) {
+			return outputs, err
+		}
+		if err != nil {
+			validationErrors = append(validationErrors, errorMessages(err)...)
+		}
+		isFogDeployment, hubId, err = this.IsFogDeployment(token, task, deployment)
+		if err != nil && IsRetryable(err) {
+			return outputs, err
+		}
+		if err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+		err = deployment.Validate(deploymentmodel.ValidateRequest, map[string]bool{"service": true}, deploymentmodel.DeploymentXmlValidator)
+		if err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+
+	renderedDeployment, err := json.Marshal(deployment)
+	if err != nil {
+		return outputs, err
+	}
+	validationErrorsJson, err := json.Marshal(validationErrors)
+	if err != nil {
+		return outputs, err
+	}
+
+	outputs = map[string]interface{}{
+		"dry_run":            true,
+		"dry_run_deployment": string(renderedDeployment),
+		"dry_run_valid":      len(validationErrors) == 0,
+		"dry_run_errors":     string(validationErrorsJson),
+		"is_fog_deployment":  isFogDeployment,
+		"fog_hub":            hubId,
+	}
+	autoSelections, exists, err := this.getAutoSelections(task, deployment)
+	if err != nil {
+		return outputs, err
+	}
+	if exists {
+		outputs["auto_selections"] = autoSelections
+	}
+	err = this.setSkippedElements(task, deployment, outputs, nil)
+	if err != nil {
+		return outputs, err
+	}
+	return outputs, nil
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/camunda"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+const (
+	ErrCodeInvalidInput   = "invalid_input"
+	ErrCodeInvalidElement = "invalid_element"
+	ErrCodePrepareFailed  = "prepare_failed"
+	ErrCodeFogPlacement   = "fog_placement_failed"
+	ErrCodeDeployFailed   = "deploy_failed"
+	ErrCodeUpstreamFailed = "upstream_failed"
+	ErrCodeUnknown        = "unknown"
+)
+
+// DeploymentError describes the cause of a failed task in a form that bpmn error paths can branch on.
+// Error() returns the message of the wrapped error.
+type DeploymentError struct {
+	Code       string         `json:"code"`
+	BpmnId     string         `json:"bpmn_id,omitempty"`
+	HttpStatus int            `json:"http_status,omitempty"`
+	Message    string         `json:"message"`
+	Retryable  bool           `json:"retryable,omitempty"`
+	Elements   []ElementError `json:"elements,omitempty"`
+	err        error
+}
+
+// ElementError describes an invalid configuration of a deployment element
+type ElementError struct {
+	BpmnId  string `json:"bpmn_id"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+func (this *DeploymentError) Error() string {
+	return this.err.Error()
+}
+
+func (this *DeploymentError) Unwrap() error {
+	return this.err
+}
+
+// withErrorCode wraps err in a DeploymentError with the given code. errors that already contain a DeploymentError are returned unchanged.
+func withErrorCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var deploymentError *DeploymentError
+	if errors.As(err, &deploymentError) {
+		return err
+	}
+	return &DeploymentError{Code: code, Message: err.Error(), err: err}
+}
+
+// newElementErrors combines the errors of all invalid elements into one DeploymentError.
+// a single element error keeps its message; multiple errors are listed with bpmn id and element name.
+func newElementErrors(elementErrors []ElementError) error {
+	if len(elementErrors) == 0 {
+		return nil
+	}
+	if len(elementErrors) == 1 {
+		return &DeploymentError{
+			Code:     ErrCodeInvalidElement,
+			BpmnId:   elementErrors[0].BpmnId,
+			Message:  elementErrors[0].Message,
+			Elements: elementErrors,
+			err:      errors.New(elementErrors[0].Message),
+		}
+	}
+	messages := []string{}
+	for _, elementError := range elementErrors {
+		messages = append(messages, fmt.Sprintf("%v (%v): %v", elementError.BpmnId, elementError.Name, elementError.Message))
+	}
+	err := fmt.Errorf("%v invalid elements: %v", len(elementErrors), strings.Join(messages, "; "))
+	return &DeploymentError{
+		Code:     ErrCodeInvalidElement,
+		Message:  err.Error(),
+		Elements: elementErrors,
+		err:      err,
+	}
+}
+
+// errorMessages returns the messages of all invalid elements or the error message, if err is not caused by invalid elements
+func errorMessages(err error) []string {
+	var deploymentError *DeploymentError
+	if !errors.As(err, &deploymentError) || len(deploymentError.Elements) == 0 {
+		return []string{err.Error()}
+	}
+	result := []string{}
+	for _, elementError := range deploymentError.Elements {
+		result = append(result, elementError.Message)
+	}
+	return result
+}
+
+// newHttpError creates a DeploymentError for an unexpected upstream response; err is the error returned by Error().
+// 5xx, 408 and 429 responses are retryable.
+func newHttpError(code string, statusCode int, body string, err error) error {
+	retryable := statusCode >= 500 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+	return &DeploymentError{Code: code, HttpStatus: statusCode, Message: body, Retryable: retryable, err: err}
+}
+
+// newTransientError creates a retryable DeploymentError for failures like network errors and timeouts
+func newTransientError(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &DeploymentError{Code: code, Message: err.Error(), Retryable: true, err: err}
+}
+
+// IsRetryable returns true if err is caused by a transient failure
+func IsRetryable(err error) bool {
+	var deploymentError *DeploymentError
+	return errors.As(err, &deploymentError) && deploymentError.Retryable
+}
+
+func toDeploymentError(err error) DeploymentError {
+	var deploymentError *DeploymentError
+	if errors.As(err, &deploymentError) {
+		result := *deploymentError
+		result.Message = err.Error()
+		return result
+	}
+	return DeploymentError{Code: ErrCodeUnknown, Message: err.Error(), err: err}
+}
+
+// reportError stores the DeploymentError in the variables map of the process instance
+// and sends the failed event of the process instance with the error as variable.
+func (this *ProcessDeployment) reportError(task model.CamundaExternalTask, err error) {
+	deploymentError := toDeploymentError(err)
+	variableName := this.libConfig.CamundaWorkerTopic + "_error"
+	setErr := this.smartServiceRepo.SetVariables(task.ProcessInstanceId, map[string]interface{}{variableName: deploymentError})
+	if setErr != nil {
+		this.libConfig.GetLogger().Error("unable to store deployment error in variables", "error", setErr)
+	}
+	errorJson, marshalErr := json.Marshal(deploymentError)
+	if marshalErr != nil {
+		this.libConfig.GetLogger().Error("unable to marshal deployment error", "error", marshalErr)
+		return
+	}
+	sendErr := camunda.SendEventTrigger(this.libConfig, processInstanceIdToTaskFailedEventId(task.ProcessInstanceId), map[string]model.CamundaVariable{
+		variableName: {Type: "String", Value: string(errorJson)},
+	})
+	if sendErr != nil {
+		this.libConfig.GetLogger().Error("unable to send failed event trigger", "error", sendErr)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// deployFanOut creates one deployment per member device of the selected device group.
+// every element with the device group selection gets the member device as selection.
+// if one deployment fails, all deployments created by this attempt are removed; reused modules of previous attempts are kept.
+func (this *ProcessDeployment) deployFanOut(task model.CamundaExternalTask, userId string, token auth.Token, modelId string, existingModules []model.SmartServiceModule) (modules []model.Module, outputs map[string]interface{}, err error) {
+	deployment, err := this.prepareDeployment(token, task, modelId)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to prepare process deployment", "error", err)
+		return modules, outputs, err
+	}
+	err = this.UseVariables(task, &deployment)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to use variables", "error", err)
+		return modules, outputs, err
+	}
+	groupId, err := getFanOutGroupId(deployment)
+	if err != nil {
+		return modules, outputs, withErrorCode(ErrCodeInvalidInput, err)
+	}
+	group, err := this.GetGroup(token, groupId)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to get device group", "error", err)
+		return modules, outputs, err
+	}
+	if len(group.DeviceIds) == 0 {
+		return modules, outputs, withErrorCode(ErrCodeInvalidInput, fmt.Errorf("fan-out device group %v has no devices", groupId))
+	}
+
+	outputs = map[string]interface{}{}
+	deploymentIds := map[string]string{}
+	created := []model.Module{}
+	for _, deviceId := range group.DeviceIds {
+		moduleId := this.getModuleId(task) + "." + deviceId
+		var module model.Module
+		var moduleOutputs map[string]interface{}
+		existingModule, exists, err := this.findReusableModule(token, existingModules, moduleId)
+		if err != nil {
+			err = fmt.Errorf("unable to check existing module %v: %w", moduleId, err)
+			this.Undo(created, err)
+			return nil, nil, err
+		}
+		if exists {
+			var reused []model.Module
+			reused, moduleOutputs, err = this.reuseExistingModule(task, userId, existingModule)
+			if err == nil {
+				module = reused[0]
+			}
+		} else {
+			var deviceDeployment deploymentmodel.Deployment
+			deviceDeployment, err = substituteGroupSelection(deployment, groupId, deviceId)
+			if err == nil {
+				module, moduleOutputs, err = this.deployPrepared(task, userId, token, deviceDeployment, moduleId)
+			}
+			if err == nil {
+				created = append(created, module)
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("unable to deploy process for device %v of group %v: %w", deviceId, groupId, err)
+			this.Undo(created, err)
+			return nil, nil, err
+		}
+		modules = append(modules, module)
+		for key, value := range moduleOutputs {
+			outputs[deviceId+"."+key] = value
+		}
+		deploymentIds[deviceId], _ = moduleOutputs["process_deployment_id"].(string)
+	}
+	deploymentIdsJson, err := json.Marshal(deploymentIds)
+	if err != nil {
+		this.Undo(created, err)
+		return nil, nil, err
+	}
+	outputs["process_deployment_ids"] = string(deploymentIdsJson)
+	return modules, outputs, nil
+}
+
+func getFanOutGroupId(deployment deploymentmodel.Deployment) (groupId string, err error) {
+	for _, element := range deployment.Elements {
+		selection := getElementSelection(&element)
+		if selection == nil || selection.SelectedDeviceGroupId == nil {
+			continue
+		}
+		if groupId != "" && groupId != *selection.SelectedDeviceGroupId {
+			return "", errors.New("fan-out needs all device group selections to use the same group")
+		}
+		groupId = *selection.SelectedDeviceGroupId
+	}
+	if groupId == "" {
+		return "", errors.New("fan-out needs at least one device group selection")
+	}
+	return groupId, nil
+}
+
+// substituteGroupSelection returns a copy of the deployment, where the device group selection is replaced by the device
+func substituteGroupSelection(deployment deploymentmodel.Deployment, groupId string, deviceId string) (result deploymentmodel.Deployment, err error) {
+	temp, err := json.Marshal(deployment)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(temp, &result)
+	if err != nil {
+		return result, err
+	}
+	for _, element := range result.Elements {
+		selection := getElementSelection(&element)
+		if selection == nil || selection.SelectedDeviceGroupId == nil || *selection.SelectedDeviceGroupId != groupId {
+			continue
+		}
+		id := deviceId
+		selection.SelectedDeviceId = &id
+		selection.SelectedDeviceGroupId = nil
+	}
+	return result, nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"time"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// getMsgEventFlowId returns event.flow_id or resolves event.flow_name by config.EventFlowIds or the flow-repository.
+// if neither is set, the config.ElementEventFlowIds entry of the element BpmnId or config.DefaultEventFlowId is used.
+func (this *ProcessDeployment) getMsgEventFlowId(task model.CamundaExternalTask, element *deploymentmodel.Element) (string, error) {
+	flowIdParameter := this.config.WorkerParamPrefix + element.BpmnId + ".event.flow_id"
+	flowId, exists, err := getStringVariable(task, flowIdParameter)
+	if err != nil || exists {
+		return flowId, err
+	}
+	flowNameParameter := this.config.WorkerParamPrefix + element.BpmnId + ".event.flow_name"
+	flowName, exists, err := getStringVariable(task, flowNameParameter)
+	if err != nil {
+		return "", err
+	}
+	if !exists || flowName == "" {
+		if flowId, ok := this.config.ElementEventFlowIds[element.BpmnId]; ok {
+			return flowId, nil
+		}
+		if this.config.DefaultEventFlowId != "" {
+			return this.config.DefaultEventFlowId, nil
+		}
+		return "", fmt.Errorf("missing %v parameter", flowIdParameter)
+	}
+	if flowId, ok := this.config.EventFlowIds[flowName]; ok {
+		return flowId, nil
+	}
+	if this.config.FlowRepoUrl == "" {
+		return "", fmt.Errorf("unknown flow %v in %v", flowName, flowNameParameter)
+	}
+	token, err := this.getInstanceUserToken(task)
+	if err != nil {
+		return "", err
+	}
+	flowId, err = this.GetFlowIdByName(token, flowName)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %v: %w", flowNameParameter, err)
+	}
+	return flowId, nil
+}
+
+type Flow struct {
+	Id   string `json:"_id"`
+	Name string `json:"name"`
+}
+
+type FlowList struct {
+	Flows []Flow `json:"flows"`
+}
+
+// GetFlowIdByName returns the id of the flow with exactly the given name
+func (this *ProcessDeployment) GetFlowIdByName(token auth.Token, name string) (flowId string, err error) {
+	client := http.Client{
+		Timeout: 5 * time.Second,
+	}
+	req, err := http.NewRequest(
+		"GET",
+		this.config.FlowRepoUrl+"/flow?"+url.Values{"search": {name}}.Encode(),
+		nil,
+	)
+	if err != nil {
+		debug.PrintStack()
+		return "", err
+	}
+	req.Header.Set("Authorization", token.Jwt())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		debug.PrintStack()
+		return "", newTransientError(ErrCodeUpstreamFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		debug.PrintStack()
+		temp, _ := io.ReadAll(resp.Body)
+		return "", newHttpError(ErrCodeUpstreamFailed, resp.StatusCode, string(temp), fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp)))
+	}
+	list := FlowList{}
+	err = json.NewDecoder(resp.Body).Decode(&list)
+	if err != nil {
+		_, _ = io.ReadAll(resp.Body) //ensure empty body to enable connection reuse and prevent memory leaks
+		return "", withErrorCode(ErrCodeUpstreamFailed, fmt.Errorf("unable to decode flow list: %w", err))
+	}
+	matches := []string{}
+	for _, flow := range list.Flows {
+		if flow.Name == name {
+			matches = append(matches, flow.Id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("unknown flow %v", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("flow name %v is not unique (%v flows)", name, len(matches))
+	}
+}
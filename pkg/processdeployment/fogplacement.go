@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+const (
+	FogPlacementStrategyFirstFit         = "first_fit"
+	FogPlacementStrategyMostDevicesLocal = "most_devices_local"
+	FogPlacementStrategyPreferredHub     = "preferred_hub"
+	FogPlacementStrategyLeastLoaded      = "least_loaded"
+)
+
+func (this *ProcessDeployment) getFogHubId(task model.CamundaExternalTask) (string, error) {
+	result, _, err := getStringVariable(task, this.config.WorkerParamPrefix+"fog_hub_id")
+	return result, err
+}
+
+func (this *ProcessDeployment) getFogPlacementStrategy(task model.CamundaExternalTask) (string, error) {
+	strategyVariable := this.config.WorkerParamPrefix + "fog_placement_strategy"
+	strategy, _, err := getStringVariable(task, strategyVariable)
+	if err != nil {
+		return "", err
+	}
+	switch strategy {
+	case "":
+		return FogPlacementStrategyFirstFit, nil
+	case FogPlacementStrategyFirstFit, FogPlacementStrategyMostDevicesLocal, FogPlacementStrategyPreferredHub, FogPlacementStrategyLeastLoaded:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown %v %v", strategyVariable, strategy)
+	}
+}
+
+// selectFogHub selects one of the candidate hubs (every hub contains all devices of the deployment) by the fog placement strategy.
+// candidates are in the order of the fog sync service; ties are resolved by this order.
+func (this *ProcessDeployment) selectFogHub(token auth.Token, task model.CamundaExternalTask, strategy string, candidates []Hub) (hubId string, err error) {
+	switch strategy {
+	case FogPlacementStrategyMostDevicesLocal:
+		//every candidate holds all devices of the process; the hub with the most local devices in total is used
+		selected := candidates[0]
+		for _, hub := range candidates[1:] {
+			if len(hub.DeviceLocalIds) > len(selected.DeviceLocalIds) {
+				selected = hub
+			}
+		}
+		return selected.Id, nil
+	case FogPlacementStrategyPreferredHub:
+		preferred := []string{}
+		preferredVariable := this.config.WorkerParamPrefix + "fog_preferred_hub_ids"
+		exists, err := getJsonVariable(task, preferredVariable, &preferred)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", fmt.Errorf("missing %v for fog_placement_strategy %v", preferredVariable, strategy)
+		}
+		for _, preferredHubId := range preferred {
+			if slices.ContainsFunc(candidates, func(hub Hub) bool { return hub.Id == preferredHubId }) {
+				return preferredHubId, nil
+			}
+		}
+		return candidates[0].Id, nil
+	case FogPlacementStrategyLeastLoaded:
+		selected := ""
+		minCount := 0
+		for _, hub := range candidates {
+			metadata, err, code := this.GetFogSyncMetadata(token, hub.Id, "")
+			if err != nil {
+				return "", newHttpError(ErrCodeUpstreamFailed, code, err.Error(), fmt.Errorf("unable to get fog sync metadata of %v: %w", hub.Id, err))
+			}
+			count := 0
+			for _, deployment := range metadata {
+				if !deployment.MarkedForDelete {
+					count++
+				}
+			}
+			if selected == "" || count < minCount {
+				selected = hub.Id
+				minCount = count
+			}
+		}
+		return selected, nil
+	default:
+		return candidates[0].Id, nil
+	}
+}
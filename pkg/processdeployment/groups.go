@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"fmt"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+)
+
+// checkGroupDevices returns an error for every grouped element, that does not use the same device as the first element of its group
+func checkGroupDevices(deployment *deploymentmodel.Deployment) (result []ElementError) {
+	type groupDevice struct {
+		bpmnId   string
+		deviceId string
+	}
+	groups := map[string]groupDevice{}
+	for _, element := range deployment.Elements {
+		if element.Group == nil || *element.Group == "" {
+			continue
+		}
+		selection := getElementSelection(&element)
+		if selection == nil || selection.SelectedDeviceId == nil {
+			continue
+		}
+		first, ok := groups[*element.Group]
+		if !ok {
+			groups[*element.Group] = groupDevice{bpmnId: element.BpmnId, deviceId: *selection.SelectedDeviceId}
+			continue
+		}
+		if first.deviceId != *selection.SelectedDeviceId {
+			result = append(result, ElementError{
+				BpmnId:  element.BpmnId,
+				Name:    element.Name,
+				Message: fmt.Sprintf("element group %v expects device %v (selected for %v) but %v is selected", *element.Group, first.deviceId, first.bpmnId, *selection.SelectedDeviceId),
+			})
+		}
+	}
+	return result
+}
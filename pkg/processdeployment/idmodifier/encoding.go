@@ -17,13 +17,21 @@
 package idmodifier
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"net/url"
 	"strings"
 )
 
 const Seperator = "$"
 
+const ServiceGroupSelectionModifier = "service_group_selection"
+
+// KnownModifiers contains all modifier keys accepted by SplitModifier and JoinModifier
+var KnownModifiers = map[string]bool{
+	ServiceGroupSelectionModifier: true,
+}
+
 func DecodeModifierParameter(parameter string) (result map[string][]string, err error) {
 	return url.ParseQuery(parameter)
 }
@@ -32,18 +40,63 @@ func EncodeModifierParameter(parameter map[string][]string) (result string) {
 	return url.Values(parameter).Encode()
 }
 
-func SplitModifier(id string) (pureId string, modifier map[string][]string) {
+// SplitModifier splits an id like "id$key=value" into the pure id and its modifier.
+// unparsable modifiers and unknown modifier keys are returned as error.
+func SplitModifier(id string) (pureId string, modifier map[string][]string, err error) {
 	parts := strings.SplitN(id, Seperator, 2)
 	pureId = parts[0]
 	if len(parts) < 2 {
-		return
+		return pureId, nil, nil
 	}
-	var err error
 	modifier, err = DecodeModifierParameter(parts[1])
 	if err != nil {
-		log.Println("WARNING: unable to parse modifier parts as Modifier --> ignore modifiers")
-		modifier = nil
-		return
+		return pureId, nil, fmt.Errorf("unable to parse modifier of %v: %w", id, err)
+	}
+	err = ValidateModifier(modifier)
+	if err != nil {
+		return pureId, nil, fmt.Errorf("invalid modifier of %v: %w", id, err)
+	}
+	return pureId, modifier, nil
+}
+
+// PureId returns the id without its modifier; the modifier is not validated
+func PureId(id string) string {
+	return strings.SplitN(id, Seperator, 2)[0]
+}
+
+// JoinModifier builds an id like "id$key=value"; the pure id is returned if the modifier is empty
+func JoinModifier(pureId string, modifier map[string][]string) (id string, err error) {
+	if strings.Contains(pureId, Seperator) {
+		return "", fmt.Errorf("id %v already contains a modifier", pureId)
+	}
+	if len(modifier) == 0 {
+		return pureId, nil
+	}
+	err = ValidateModifier(modifier)
+	if err != nil {
+		return "", err
+	}
+	return pureId + Seperator + EncodeModifierParameter(modifier), nil
+}
+
+// NormalizeId returns the id with a canonical encoded modifier
+func NormalizeId(id string) (string, error) {
+	pureId, modifier, err := SplitModifier(id)
+	if err != nil {
+		return "", err
+	}
+	return JoinModifier(pureId, modifier)
+}
+
+// ValidateModifier checks that every key is a known modifier and has at least one value
+func ValidateModifier(modifier map[string][]string) error {
+	for key, values := range modifier {
+		if !KnownModifiers[key] {
+			return fmt.Errorf("unknown modifier %v", key)
+		}
+		if len(values) == 0 {
+			return errors.New("missing value for modifier " + key)
+		}
 	}
-	return
+	return nil
 }
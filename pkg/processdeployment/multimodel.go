@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// deployProcessModels deploys every referenced process model with its own variable namespace.
+// if one deployment fails, all deployments created by this attempt are removed; reused modules of previous attempts are kept.
+func (this *ProcessDeployment) deployProcessModels(task model.CamundaExternalTask, userId string, token auth.Token, processModels []ProcessModelRef, existingModules []model.SmartServiceModule) (modules []model.Module, outputs map[string]interface{}, err error) {
+	outputs = map[string]interface{}{}
+	deploymentIds := map[string]string{}
+	created := []model.Module{}
+	for _, ref := range processModels {
+		moduleId := this.getModuleId(task) + "." + ref.Alias
+		var module model.Module
+		var moduleOutputs map[string]interface{}
+		existingModule, exists, err := this.findReusableModule(token, existingModules, moduleId)
+		if err != nil {
+			err = fmt.Errorf("unable to check existing module %v: %w", moduleId, err)
+			this.Undo(created, err)
+			return nil, nil, err
+		}
+		if exists {
+			var reused []model.Module
+			reused, moduleOutputs, err = this.reuseExistingModule(task, userId, existingModule)
+			if err == nil {
+				module = reused[0]
+			}
+		} else {
+			module, moduleOutputs, err = this.deployProcessModel(this.getNamespacedTask(task, ref.Alias), userId, token, ref.ModelId, moduleId)
+			if err == nil {
+				created = append(created, module)
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("unable to deploy %v (%v): %w", ref.Alias, ref.ModelId, err)
+			this.Undo(created, err)
+			return nil, nil, err
+		}
+		modules = append(modules, module)
+		for key, value := range moduleOutputs {
+			outputs[ref.Alias+"."+key] = value
+		}
+		deploymentIds[ref.Alias], _ = moduleOutputs["process_deployment_id"].(string)
+	}
+	deploymentIdsJson, err := json.Marshal(deploymentIds)
+	if err != nil {
+		this.Undo(created, err)
+		return nil, nil, err
+	}
+	outputs["process_deployment_ids"] = string(deploymentIdsJson)
+	return modules, outputs, nil
+}
+
+func (this *ProcessDeployment) dryRunProcessModels(token auth.Token, task model.CamundaExternalTask, processModels []ProcessModelRef) (outputs map[string]interface{}, err error) {
+	if len(processModels) == 1 && processModels[0].Alias == "" {
+		return this.DryRun(token, task, processModels[0].ModelId)
+	}
+	outputs = map[string]interface{}{
+		"dry_run":       true,
+		"dry_run_valid": true,
+	}
+	for _, ref := range processModels {
+		modelOutputs, err := this.DryRun(token, this.getNamespacedTask(task, ref.Alias), ref.ModelId)
+		if err != nil {
+			return outputs, fmt.Errorf("unable to prepare %v (%v): %w", ref.Alias, ref.ModelId, err)
+		}
+		for key, value := range modelOutputs {
+			outputs[ref.Alias+"."+key] = value
+		}
+		if valid, _ := modelOutputs["dry_run_valid"].(bool); !valid {
+			outputs["dry_run_valid"] = false
+		}
+	}
+	return outputs, nil
+}
+
+// getNamespacedTask returns a copy of the task, in which the variables of the alias namespace
+// ({{WorkerParamPrefix}}{{alias}}.{{name}}) override the variables without namespace ({{WorkerParamPrefix}}{{name}})
+func (this *ProcessDeployment) getNamespacedTask(task model.CamundaExternalTask, alias string) model.CamundaExternalTask {
+	namespace := this.config.WorkerParamPrefix + alias + "."
+	variables := map[string]model.CamundaVariable{}
+	for key, value := range task.Variables {
+		variables[key] = value
+	}
+	for key, value := range task.Variables {
+		if strings.HasPrefix(key, namespace) {
+			variables[this.config.WorkerParamPrefix+strings.TrimPrefix(key, namespace)] = value
+		}
+	}
+	task.Variables = variables
+	return task
+}
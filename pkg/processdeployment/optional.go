@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// isOptionalElement returns true if the element may be deployed without selection.
+// <BpmnId>.optional takes precedence over the model-wide optional_elements.
+func (this *ProcessDeployment) isOptionalElement(task model.CamundaExternalTask, element *deploymentmodel.Element) (bool, error) {
+	elementVariable := this.config.WorkerParamPrefix + element.BpmnId + ".optional"
+	optional, exists, err := getBoolVariable(task, elementVariable)
+	if err != nil {
+		return false, fmt.Errorf("invalid %v: %w", elementVariable, err)
+	}
+	if exists {
+		return optional, nil
+	}
+	modelVariable := this.config.WorkerParamPrefix + "optional_elements"
+	optional, _, err = getBoolVariable(task, modelVariable)
+	if err != nil {
+		return false, fmt.Errorf("invalid %v: %w", modelVariable, err)
+	}
+	return optional, nil
+}
+
+// hasSelectedValue returns true if a device, device-group, import or generic event source is selected
+func hasSelectedValue(selection *deploymentmodel.Selection) bool {
+	return selection.SelectedDeviceId != nil ||
+		selection.SelectedDeviceGroupId != nil ||
+		selection.SelectedImportId != nil ||
+		selection.SelectedGenericEventSource != nil
+}
+
+// getSkippedElements returns the BpmnIds of all optional elements that are deployed without selection
+func (this *ProcessDeployment) getSkippedElements(task model.CamundaExternalTask, deployment deploymentmodel.Deployment) (result []string, err error) {
+	for _, element := range deployment.Elements {
+		selection := getElementSelection(&element)
+		if selection == nil || hasSelectedValue(selection) {
+			continue
+		}
+		optional, err := this.isOptionalElement(task, &element)
+		if err != nil {
+			return result, err
+		}
+		if optional {
+			result = append(result, element.BpmnId)
+		}
+	}
+	return result, nil
+}
+
+// setSkippedElements adds the skipped elements as json list to the outputs and, if moduleData is not nil, to the module data
+func (this *ProcessDeployment) setSkippedElements(task model.CamundaExternalTask, deployment deploymentmodel.Deployment, outputs map[string]interface{}, moduleData map[string]interface{}) error {
+	skipped, err := this.getSkippedElements(task, deployment)
+	if err != nil {
+		return err
+	}
+	if len(skipped) == 0 {
+		if moduleData != nil {
+			delete(moduleData, "skipped_elements")
+		}
+		return nil
+	}
+	temp, err := json.Marshal(skipped)
+	if err != nil {
+		return err
+	}
+	outputs["skipped_elements"] = string(temp)
+	if moduleData != nil {
+		moduleData["skipped_elements"] = skipped
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// outboxEntry is a pending done event of a deployment.
+// EventId is empty as long as the readiness of the deployment is unknown.
+type outboxEntry struct {
+	DeploymentId    string    `json:"deployment_id"`
+	UserId          string    `json:"user_id"`
+	IsFogDeployment bool      `json:"is_fog_deployment"`
+	HubId           string    `json:"hub_id"`
+	ReadyDeadline   time.Time `json:"ready_deadline"`
+	EventId         string    `json:"event_id"`
+	Attempts        int       `json:"attempts"`
+	NextAttempt     time.Time `json:"next_attempt"`
+}
+
+// eventOutbox waits for deployments to be ready and sends their done events.
+// entries are stored in file (if set), to be continued after a restart.
+type eventOutbox struct {
+	file       string
+	backoff    time.Duration
+	maxBackoff time.Duration
+	logger     *slog.Logger
+	isReady    func(entry outboxEntry) error
+	send       func(eventId string) error
+
+	mux     sync.Mutex
+	entries map[string]outboxEntry
+	notify  chan struct{}
+}
+
+func newEventOutbox(file string, backoff time.Duration, maxBackoff time.Duration, logger *slog.Logger, isReady func(entry outboxEntry) error, send func(eventId string) error) (*eventOutbox, error) {
+	result := &eventOutbox{
+		file:       file,
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+		logger:     logger,
+		isReady:    isReady,
+		send:       send,
+		entries:    map[string]outboxEntry{},
+		notify:     make(chan struct{}, 1),
+	}
+	if file == "" {
+		return result, nil
+	}
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == 0 {
+		return result, nil
+	}
+	var entries []outboxEntry
+	err = json.Unmarshal(content, &entries)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		result.entries[entry.DeploymentId] = entry
+	}
+	return result, nil
+}
+
+// Add stores the entry and wakes the outbox loop. an existing entry of the same deployment is replaced.
+func (this *eventOutbox) Add(entry outboxEntry) error {
+	entry.NextAttempt = time.Now().Add(this.backoff)
+	this.mux.Lock()
+	this.entries[entry.DeploymentId] = entry
+	err := this.persist()
+	this.mux.Unlock()
+	select {
+	case this.notify <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Run processes due entries until ctx is done. on shutdown, entries with a known event are sent one last time.
+func (this *eventOutbox) Run(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			wait := time.Minute
+			if next, ok := this.processDue(ctx); ok {
+				wait = max(time.Until(next), 0)
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				this.drain()
+				return
+			case <-this.notify:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// processDue handles all entries with NextAttempt in the past and returns the earliest NextAttempt of the remaining entries
+func (this *eventOutbox) processDue(ctx context.Context) (next time.Time, ok bool) {
+	now := time.Now()
+	due := []outboxEntry{}
+	this.mux.Lock()
+	for _, entry := range this.entries {
+		if !entry.NextAttempt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	this.mux.Unlock()
+
+	for _, entry := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		entry, done := this.process(entry)
+		this.mux.Lock()
+		if current, exists := this.entries[entry.DeploymentId]; exists && current.NextAttempt.After(now) {
+			//entry has been replaced by Add() while processing
+		} else if done {
+			delete(this.entries, entry.DeploymentId)
+		} else {
+			this.entries[entry.DeploymentId] = entry
+		}
+		err := this.persist()
+		this.mux.Unlock()
+		if err != nil {
+			this.logger.Error("unable to persist event outbox", "error", err)
+		}
+	}
+
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	for _, entry := range this.entries {
+		if !ok || entry.NextAttempt.Before(next) {
+			next = entry.NextAttempt
+			ok = true
+		}
+	}
+	return next, ok
+}
+
+// process checks the readiness of the deployment (if still unknown) and sends the event.
+// returns done == true, if the event has been sent.
+func (this *eventOutbox) process(entry outboxEntry) (result outboxEntry, done bool) {
+	if entry.EventId == "" {
+		err := this.isReady(entry)
+		switch {
+		case err == nil:
+			entry.EventId = deploymentIdToEventId(entry.DeploymentId)
+			entry.Attempts = 0
+		case time.Now().After(entry.ReadyDeadline):
+			this.logger.Error("deployment not ready", "deploymentId", entry.DeploymentId, "error", err)
+			entry.EventId = deploymentIdToFailedEventId(entry.DeploymentId)
+			entry.Attempts = 0
+		default:
+			this.logger.Debug("deployment not yet ready", "deploymentId", entry.DeploymentId, "error", err)
+			return this.retryLater(entry), false
+		}
+	}
+	err := this.send(entry.EventId)
+	if err != nil {
+		this.logger.Error("unable to send event trigger", "eventId", entry.EventId, "error", err)
+		return this.retryLater(entry), false
+	}
+	return entry, true
+}
+
+func (this *eventOutbox) retryLater(entry outboxEntry) outboxEntry {
+	entry.Attempts++
+	backoff := this.backoff
+	for i := 1; i < entry.Attempts && backoff < this.maxBackoff; i++ {
+		backoff = 2 * backoff
+	}
+	entry.NextAttempt = time.Now().Add(min(backoff, this.maxBackoff))
+	return entry
+}
+
+// drain tries to send all entries with a known event. entries with unknown readiness stay in the outbox.
+func (this *eventOutbox) drain() {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	for id, entry := range this.entries {
+		if entry.EventId == "" {
+			continue
+		}
+		err := this.send(entry.EventId)
+		if err != nil {
+			this.logger.Error("unable to send event trigger on shutdown", "eventId", entry.EventId, "error", err)
+			continue
+		}
+		delete(this.entries, id)
+	}
+	err := this.persist()
+	if err != nil {
+		this.logger.Error("unable to persist event outbox", "error", err)
+	}
+}
+
+// persist writes all entries to this.file; expects this.mux to be locked
+func (this *eventOutbox) persist() error {
+	if this.file == "" {
+		return nil
+	}
+	entries := make([]outboxEntry, 0, len(this.entries))
+	for _, entry := range this.entries {
+		entries = append(entries, entry)
+	}
+	content, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(this.file), filepath.Base(this.file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = temp.Write(content)
+	if err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	err = temp.Close()
+	if err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), this.file)
+}
@@ -23,61 +23,86 @@ import (
 	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
 	"github.com/SENERGY-Platform/process-deployment/lib/model/deviceselectionmodel"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
-	"strconv"
+	"github.com/SENERGY-Platform/smart-service-module-worker-process/pkg/processdeployment/idmodifier"
+	"sort"
 	"strings"
 )
 
 func (this *ProcessDeployment) getModuleData(task model.CamundaExternalTask) (result map[string]interface{}) {
 	result = map[string]interface{}{}
-	variable, ok := task.Variables[this.config.WorkerParamPrefix+"module_data"]
-	if !ok {
-		return result
-	}
-	str, ok := variable.Value.(string)
-	if !ok {
-		return result
-	}
-	err := json.Unmarshal([]byte(str), &result)
-	if err != nil {
+	_, err := getJsonVariable(task, this.config.WorkerParamPrefix+"module_data", &result)
+	if err != nil || result == nil {
 		return map[string]interface{}{}
 	}
 	return result
 }
 
-func (this *ProcessDeployment) getProcessModelId(task model.CamundaExternalTask) string {
-	variable, ok := task.Variables[this.config.WorkerParamPrefix+"process_model_id"]
-	if !ok {
-		return ""
-	}
-	result, ok := variable.Value.(string)
-	if !ok {
-		return ""
-	}
-	return result
+type ProcessModelRef struct {
+	Alias   string //empty for single process model deployments
+	ModelId string
 }
 
-func (this *ProcessDeployment) setStartParameter(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment) error {
-	for key, variable := range task.Variables {
-		prefix := this.config.WorkerParamPrefix + "start_parameter.default."
-		if strings.HasPrefix(key, prefix) {
-			paramName := strings.TrimPrefix(key, prefix)
-			for i, param := range deployment.StartParameter {
-				if param.Id == paramName {
-					temp, ok := variable.Value.(string)
-					if !ok {
-						b, err := json.Marshal(variable.Value)
-						if err != nil {
-							return err
-						}
-						temp = string(b)
-					}
-					param.Default = temp
-				}
-				deployment.StartParameter[i] = param
+// getProcessModels returns the process models referenced by process_model_ids (list or alias to id map)
+// or, if process_model_ids is not set, by process_model_id
+func (this *ProcessDeployment) getProcessModels(task model.CamundaExternalTask) (result []ProcessModelRef, err error) {
+	variableName := this.config.WorkerParamPrefix + "process_model_ids"
+	variable, ok := task.Variables[variableName]
+	if !ok || variable.Value == nil {
+		modelId, err := this.getProcessModelId(task)
+		if err != nil || modelId == "" {
+			return result, err
+		}
+		return []ProcessModelRef{{ModelId: modelId}}, nil
+	}
+	value, err := decodeVariable(variable)
+	if err != nil {
+		return result, fmt.Errorf("unexpected value in %v: %w", variableName, err)
+	}
+	if str, ok := value.(string); ok {
+		err = json.Unmarshal([]byte(str), &value)
+		if err != nil {
+			return result, fmt.Errorf("invalid %v: %w", variableName, err)
+		}
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, element := range v {
+			modelId, ok := element.(string)
+			if !ok || modelId == "" {
+				return result, fmt.Errorf("invalid %v: expect list of model ids", variableName)
+			}
+			result = append(result, ProcessModelRef{Alias: modelId, ModelId: modelId})
+		}
+	case map[string]interface{}:
+		for alias, element := range v {
+			modelId, ok := element.(string)
+			if !ok || modelId == "" {
+				return result, fmt.Errorf("invalid %v: expect map of alias to model id", variableName)
 			}
+			result = append(result, ProcessModelRef{Alias: alias, ModelId: modelId})
 		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Alias < result[j].Alias
+		})
+	default:
+		return result, fmt.Errorf("invalid %v: expect list or map of model ids", variableName)
 	}
-	return nil
+	for _, ref := range result {
+		if ref.Alias == "" || strings.Contains(ref.Alias, ".") {
+			return result, fmt.Errorf("invalid alias in %v: %v", variableName, ref.Alias)
+		}
+	}
+	return result, nil
+}
+
+func (this *ProcessDeployment) getProcessModelId(task model.CamundaExternalTask) (string, error) {
+	result, _, err := getStringVariable(task, this.config.WorkerParamPrefix+"process_model_id")
+	return result, err
+}
+
+func (this *ProcessDeployment) getUpdateDeploymentId(task model.CamundaExternalTask) (string, error) {
+	result, _, err := getStringVariable(task, this.config.WorkerParamPrefix+"update_deployment_id")
+	return result, err
 }
 
 func (this *ProcessDeployment) setIncidentHandling(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment) error {
@@ -93,9 +118,9 @@ func (this *ProcessDeployment) setIncidentHandling(task model.CamundaExternalTas
 }
 
 func (this *ProcessDeployment) setIncidentHandlingRestart(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment) error {
-	variable, ok := task.Variables[this.config.WorkerParamPrefix+"on_incident.restart"]
-	if !ok {
-		return nil
+	value, exists, err := getBoolVariable(task, this.config.WorkerParamPrefix+"on_incident.restart")
+	if err != nil || !exists {
+		return err
 	}
 	if deployment.IncidentHandling == nil {
 		deployment.IncidentHandling = &deploymentmodel.IncidentHandling{
@@ -103,28 +128,14 @@ func (this *ProcessDeployment) setIncidentHandlingRestart(task model.CamundaExte
 			Notify:  true,
 		}
 	}
-	switch v := variable.Value.(type) {
-	case string:
-		result, err := strconv.ParseBool(v)
-		if err != nil {
-			err = fmt.Errorf("unexpected value in %v: %v (%w)", this.config.WorkerParamPrefix+"prefer_fog_deployment", v, err)
-			return err
-		}
-		deployment.IncidentHandling.Restart = result
-		return nil
-	case bool:
-		deployment.IncidentHandling.Restart = v
-		return nil
-	default:
-		err := fmt.Errorf("unexpected value in %v: %v", this.config.WorkerParamPrefix+"prefer_fog_deployment", v)
-		return err
-	}
+	deployment.IncidentHandling.Restart = value
+	return nil
 }
 
 func (this *ProcessDeployment) setIncidentHandlingNotify(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment) error {
-	variable, ok := task.Variables[this.config.WorkerParamPrefix+"on_incident.notify"]
-	if !ok {
-		return nil
+	value, exists, err := getBoolVariable(task, this.config.WorkerParamPrefix+"on_incident.notify")
+	if err != nil || !exists {
+		return err
 	}
 	if deployment.IncidentHandling == nil {
 		deployment.IncidentHandling = &deploymentmodel.IncidentHandling{
@@ -132,85 +143,99 @@ func (this *ProcessDeployment) setIncidentHandlingNotify(task model.CamundaExter
 			Notify:  true,
 		}
 	}
-	switch v := variable.Value.(type) {
-	case string:
-		result, err := strconv.ParseBool(v)
-		if err != nil {
-			err = fmt.Errorf("unexpected value in %v: %v (%w)", this.config.WorkerParamPrefix+"prefer_fog_deployment", v, err)
-			return err
-		}
-		deployment.IncidentHandling.Notify = result
-		return nil
-	case bool:
-		deployment.IncidentHandling.Notify = v
-		return nil
-	default:
-		err := fmt.Errorf("unexpected value in %v: %v", this.config.WorkerParamPrefix+"prefer_fog_deployment", v)
-		return err
-	}
+	deployment.IncidentHandling.Notify = value
+	return nil
 }
 
-func (this *ProcessDeployment) getProcessName(task model.CamundaExternalTask) string {
-	variable, ok := task.Variables[this.config.WorkerParamPrefix+"name"]
-	if !ok {
-		return ""
-	}
-	result, ok := variable.Value.(string)
-	if !ok {
-		return ""
-	}
-	return result
+func (this *ProcessDeployment) getProcessName(task model.CamundaExternalTask) (string, error) {
+	result, _, err := getStringVariable(task, this.config.WorkerParamPrefix+"name")
+	return result, err
 }
 
 func (this *ProcessDeployment) getPreferFogDeployment(task model.CamundaExternalTask) (bool, error) {
-	variable, ok := task.Variables[this.config.WorkerParamPrefix+"prefer_fog_deployment"]
-	if !ok {
-		return false, nil
-	}
-	switch v := variable.Value.(type) {
-	case string:
-		result, err := strconv.ParseBool(v)
-		if err != nil {
-			err = fmt.Errorf("unexpected value in %v: %v (%w)", this.config.WorkerParamPrefix+"prefer_fog_deployment", v, err)
-			return false, err
-		}
-		return result, nil
-	case bool:
-		return v, nil
-	default:
-		err := fmt.Errorf("unexpected value in %v: %v", this.config.WorkerParamPrefix+"prefer_fog_deployment", v)
-		return false, err
-	}
+	result, _, err := getBoolVariable(task, this.config.WorkerParamPrefix+"prefer_fog_deployment")
+	return result, err
 }
 
-func (this *ProcessDeployment) setSelection(task model.CamundaExternalTask, element *deploymentmodel.Element) error {
-	var elementSelection *deploymentmodel.Selection
+func (this *ProcessDeployment) getDryRun(task model.CamundaExternalTask) (bool, error) {
+	result, _, err := getBoolVariable(task, this.config.WorkerParamPrefix+"dry_run")
+	return result, err
+}
+
+func (this *ProcessDeployment) getFanOut(task model.CamundaExternalTask) (bool, error) {
+	result, _, err := getBoolVariable(task, this.config.WorkerParamPrefix+"fan_out")
+	return result, err
+}
+
+// getElementSelection returns the selection of the element or nil if the element has no selection
+func getElementSelection(element *deploymentmodel.Element) *deploymentmodel.Selection {
 	switch {
 	case element.Task != nil:
-		elementSelection = &element.Task.Selection
+		return &element.Task.Selection
 	case element.MessageEvent != nil:
-		elementSelection = &element.MessageEvent.Selection
+		return &element.MessageEvent.Selection
 	case element.ConditionalEvent != nil:
-		elementSelection = &element.ConditionalEvent.Selection
+		return &element.ConditionalEvent.Selection
 	default:
 		return nil
 	}
+}
 
-	selectionVariable, ok := task.Variables[this.config.WorkerParamPrefix+element.BpmnId+".selection"]
-	if !ok {
-		return errors.New("missing iot selection for " + element.BpmnId)
+func (this *ProcessDeployment) setSelection(task model.CamundaExternalTask, element *deploymentmodel.Element) error {
+	elementSelection := getElementSelection(element)
+	if elementSelection == nil {
+		return nil
 	}
-	seelctionString, ok := selectionVariable.Value.(string)
-	if !ok {
-		return errors.New("invalid iot selection for " + element.BpmnId)
+	auto, err := this.usesAutoSelection(task, element)
+	if err != nil {
+		return err
 	}
+	if auto {
+		return nil //applied by autoSelect
+	}
+
 	selection := model.IotOption{}
-	err := json.Unmarshal([]byte(seelctionString), &selection)
+	exists, err := getJsonVariable(task, this.config.WorkerParamPrefix+element.BpmnId+".selection", &selection)
 	if err != nil {
 		return fmt.Errorf("invalid iot selection for %v: %w", element.BpmnId, err)
 	}
+	if !exists {
+		_, isRef, err := this.getSelectionRef(task, element)
+		if err != nil {
+			return err
+		}
+		if isRef {
+			return nil //applied by applySelectionRefs after every element selection is set
+		}
+	}
+	if !exists && element.Group != nil && *element.Group != "" {
+		exists, err = getJsonVariable(task, this.config.WorkerParamPrefix+"group."+*element.Group+".selection", &selection)
+		if err != nil {
+			return fmt.Errorf("invalid iot selection for group %v: %w", *element.Group, err)
+		}
+	}
+	if !exists {
+		exists, err = getJsonVariable(task, this.config.WorkerParamPrefix+"default.selection", &selection)
+		if err != nil {
+			return fmt.Errorf("invalid default iot selection: %w", err)
+		}
+	}
+	if !exists {
+		optional, err := this.isOptionalElement(task, element)
+		if err != nil {
+			return err
+		}
+		if optional {
+			return nil //deployed without selection; reported by getSkippedElements
+		}
+		return errors.New("missing iot selection for " + element.BpmnId)
+	}
 
 	if selection.DeviceSelection != nil {
+		selection.DeviceSelection.DeviceId, err = idmodifier.NormalizeId(selection.DeviceSelection.DeviceId)
+		if err != nil {
+			return fmt.Errorf("invalid iot selection for %v: %w", element.BpmnId, err)
+		}
 		elementSelection.SelectedDeviceId = &selection.DeviceSelection.DeviceId
 		elementSelection.SelectedServiceId = selection.DeviceSelection.ServiceId
 		if selection.DeviceSelection.Path != nil {
@@ -256,22 +281,25 @@ func (this *ProcessDeployment) setConditionalEventVariables(task model.CamundaEx
 	if element.ConditionalEvent == nil {
 		return nil
 	}
-	for key, _ := range element.ConditionalEvent.Variables {
-		parameterName := this.config.WorkerParamPrefix + element.BpmnId + ".variables." + key
-		parameterVariable, ok := task.Variables[parameterName]
-		if !ok {
+	prefix := this.config.WorkerParamPrefix + element.BpmnId + ".variables."
+	for name := range task.Variables {
+		if !strings.HasPrefix(name, prefix) {
 			continue
 		}
-		parameterString, ok := parameterVariable.Value.(string)
-		if ok {
-			element.ConditionalEvent.Variables[key] = parameterString
-		} else {
-			jsonParameter, err := json.Marshal(parameterVariable.Value)
-			if err != nil {
-				return fmt.Errorf("unable to interpret %v parameter: %w", parameterName, err)
-			}
-			element.ConditionalEvent.Variables[key] = string(jsonParameter)
+		key := strings.TrimPrefix(name, prefix)
+		//only new variables must be js identifiers; variables of the prepared deployment may be overwritten as is
+		_, known := element.ConditionalEvent.Variables[key]
+		if !known && !jsIdentifierPattern.MatchString(key) {
+			return fmt.Errorf("invalid conditional-event variable name %v in %v", key, name)
+		}
+		value, _, err := getJsonStringVariable(task, name)
+		if err != nil {
+			return err
+		}
+		if element.ConditionalEvent.Variables == nil {
+			element.ConditionalEvent.Variables = map[string]string{}
 		}
+		element.ConditionalEvent.Variables[key] = value
 	}
 
 	return nil
@@ -281,21 +309,13 @@ func (this *ProcessDeployment) setParameter(task model.CamundaExternalTask, elem
 	if element.Task == nil {
 		return nil
 	}
-	for key, _ := range element.Task.Parameter {
-		parameterName := this.config.WorkerParamPrefix + element.BpmnId + ".parameter." + key
-		parameterVariable, ok := task.Variables[parameterName]
-		if !ok {
-			continue
+	for key := range element.Task.Parameter {
+		value, exists, err := getJsonStringVariable(task, this.config.WorkerParamPrefix+element.BpmnId+".parameter."+key)
+		if err != nil {
+			return err
 		}
-		parameterString, ok := parameterVariable.Value.(string)
-		if ok {
-			element.Task.Parameter[key] = parameterString
-		} else {
-			jsonParameter, err := json.Marshal(parameterVariable.Value)
-			if err != nil {
-				return fmt.Errorf("unable to interpret %v parameter: %w", parameterName, err)
-			}
-			element.Task.Parameter[key] = string(jsonParameter)
+		if exists {
+			element.Task.Parameter[key] = value
 		}
 	}
 
@@ -307,45 +327,28 @@ func (this *ProcessDeployment) setMsgEventConfig(task model.CamundaExternalTask,
 		return nil
 	}
 	parameterName := this.config.WorkerParamPrefix + element.BpmnId + ".event.value"
-	parameterVariable, ok := task.Variables[parameterName]
-	if ok && parameterVariable.Value != nil {
-		var err error
-		element.MessageEvent.Value, err = ensureJsonString(parameterVariable.Value)
+	value, exists, err := getVariable(task, parameterName)
+	if err != nil {
+		return err
+	}
+	if exists && value != nil {
+		element.MessageEvent.Value, err = ensureJsonString(value)
 		if err != nil {
-			return err
+			return fmt.Errorf("unexpected value in %v: %w", parameterName, err)
 		}
-		/*
-			element.MessageEvent.Value, ok = parameterVariable.Value.(string)
-			if !ok {
-				return fmt.Errorf("unable to interpret %v = %T %v parameter as string", parameterName, parameterVariable.Value, parameterVariable.Value)
-			}
-		*/
-	}
-	parameterName = this.config.WorkerParamPrefix + element.BpmnId + ".event.flow_id"
-	parameterVariable, ok = task.Variables[parameterName]
-	if !ok {
-		return fmt.Errorf("missing %v parameter", parameterName)
 	}
-	element.MessageEvent.FlowId, ok = parameterVariable.Value.(string)
-	if !ok {
-		return fmt.Errorf("unable to interpret %v parameter as string", parameterName)
+
+	element.MessageEvent.FlowId, err = this.getMsgEventFlowId(task, element)
+	if err != nil {
+		return err
 	}
 
-	parameterName = this.config.WorkerParamPrefix + element.BpmnId + ".event.use_marshaller"
-	parameterVariable, ok = task.Variables[parameterName]
-	if ok {
-		switch v := parameterVariable.Value.(type) {
-		case string:
-			var err error
-			element.MessageEvent.UseMarshaller, err = strconv.ParseBool(v)
-			if err != nil {
-				return fmt.Errorf("unable to handle %v parameter: %w", parameterName, err)
-			}
-		case bool:
-			element.MessageEvent.UseMarshaller = v
-		default:
-			return fmt.Errorf("unable to handle %v parameter with given type", parameterName)
-		}
+	useMarshaller, exists, err := getBoolVariable(task, this.config.WorkerParamPrefix+element.BpmnId+".event.use_marshaller")
+	if err != nil {
+		return err
+	}
+	if exists {
+		element.MessageEvent.UseMarshaller = useMarshaller
 	}
 
 	return nil
@@ -365,19 +368,47 @@ func ensureJsonString(value interface{}) (result string, err error) {
 	return string(buf), err
 }
 
+func (this *ProcessDeployment) setNotification(task model.CamundaExternalTask, element *deploymentmodel.Element) error {
+	if element.Notification == nil {
+		return nil
+	}
+	title, exists, err := getStringVariable(task, this.config.WorkerParamPrefix+element.BpmnId+".notification.title")
+	if err != nil {
+		return err
+	}
+	if exists {
+		element.Notification.Title = title
+	}
+	message, exists, err := getStringVariable(task, this.config.WorkerParamPrefix+element.BpmnId+".notification.message")
+	if err != nil {
+		return err
+	}
+	if exists {
+		element.Notification.Message = message
+	}
+	return nil
+}
+
 func (this *ProcessDeployment) setTime(task model.CamundaExternalTask, element *deploymentmodel.Element) error {
 	if element.TimeEvent == nil {
 		return nil
 	}
-	variableName := this.config.WorkerParamPrefix + element.BpmnId + ".time"
-	timeVariable, ok := task.Variables[variableName]
-	if !ok {
-		return nil
+	parameterName := this.config.WorkerParamPrefix + element.BpmnId + ".time"
+	timeString, exists, err := getStringVariable(task, parameterName)
+	if err != nil || !exists {
+		return err
+	}
+	location, timezoneVariable, err := this.getTimezone(task, element.BpmnId)
+	if err != nil {
+		return err
 	}
-	timeString, ok := timeVariable.Value.(string)
-	if !ok {
-		return errors.New("invalid time value in " + variableName)
+	//cron expressions are evaluated by camunda in the timezone of the engine; timezones apply only to local date-times
+	if element.TimeEvent.Type == TimeEventTypeCycle && timezoneVariable != "" && isCronExpression(timeString) {
+		this.libConfig.GetLogger().Warn("ignore timezone for cron expression; cron expressions are evaluated in the timezone of the engine", "variable", timezoneVariable, "bpmnId", element.BpmnId, "cron", strings.TrimSpace(timeString))
+	}
+	element.TimeEvent.Time, err = convertTimeEventValue(element.TimeEvent.Type, timeString, location)
+	if err != nil {
+		return fmt.Errorf("unexpected value in %v: %w", parameterName, err)
 	}
-	element.TimeEvent.Time = timeString
 	return nil
 }
@@ -25,9 +25,13 @@ import (
 	"net/http"
 	"net/url"
 	"runtime/debug"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/SENERGY-Platform/device-repository/lib/client"
+	drmodel "github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/models/go/models"
 	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/configuration"
@@ -36,7 +40,55 @@ import (
 )
 
 func New(ctx context.Context, wg *sync.WaitGroup, config Config, libConfig configuration.Config, auth *auth.Auth, smartServiceRepo SmartServiceRepo) (*ProcessDeployment, error) {
-	return &ProcessDeployment{config: config, libConfig: libConfig, auth: auth, smartServiceRepo: smartServiceRepo}, nil
+	doneEventTimeout, err := time.ParseDuration(config.DoneEventTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid done_event_timeout: %w", err)
+	}
+	doneEventBackoff, err := time.ParseDuration(config.DoneEventBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid done_event_backoff: %w", err)
+	}
+	doneEventMaxBackoff, err := time.ParseDuration(config.DoneEventMaxBackoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid done_event_max_backoff: %w", err)
+	}
+	result, err := newProcessDeployment(config, libConfig, auth, smartServiceRepo)
+	if err != nil {
+		return nil, err
+	}
+	result.doneEventTimeout = doneEventTimeout
+	result.outbox, err = newEventOutbox(config.EventOutboxFile, doneEventBackoff, doneEventMaxBackoff, libConfig.GetLogger(), result.isDeploymentReady, result.sendEventTrigger)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load event outbox: %w", err)
+	}
+	result.outbox.Run(ctx, wg)
+	return result, nil
+}
+
+// NewDryRun creates a handler that handles every task as dry-run (see ProcessDeployment.DryRun)
+func NewDryRun(ctx context.Context, wg *sync.WaitGroup, config Config, libConfig configuration.Config, auth *auth.Auth, smartServiceRepo SmartServiceRepo) (*ProcessDeployment, error) {
+	result, err := newProcessDeployment(config, libConfig, auth, smartServiceRepo)
+	if err != nil {
+		return nil, err
+	}
+	result.dryRunOnly = true
+	return result, nil
+}
+
+// newProcessDeployment creates the parts of the handler that are shared by New and NewDryRun
+func newProcessDeployment(config Config, libConfig configuration.Config, auth *auth.Auth, smartServiceRepo SmartServiceRepo) (*ProcessDeployment, error) {
+	retryTimeout, err := time.ParseDuration(config.CamundaTaskRetryTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid camunda_task_retry_timeout: %w", err)
+	}
+	return &ProcessDeployment{
+		config:           config,
+		libConfig:        libConfig,
+		auth:             auth,
+		smartServiceRepo: smartServiceRepo,
+		deviceRepo:       client.NewClient(config.DeviceRepositoryUrl, nil),
+		retryTimeout:     retryTimeout,
+	}, nil
 }
 
 type ProcessDeployment struct {
@@ -44,50 +96,147 @@ type ProcessDeployment struct {
 	libConfig        configuration.Config
 	auth             *auth.Auth
 	smartServiceRepo SmartServiceRepo
+	deviceRepo       DeviceRepo
+	dryRunOnly       bool
+	doneEventTimeout time.Duration
+	retryTimeout     time.Duration
+	outbox           *eventOutbox
 }
 
 type SmartServiceRepo interface {
 	GetInstanceUser(instanceId string) (userId string, err error)
 	UseModuleDeleteInfo(info model.ModuleDeleteInfo) error
+	ListExistingModules(processInstanceId string, query model.ModulQuery) (result []model.SmartServiceModule, err error)
+	GetVariables(processId string) (result map[string]interface{}, err error)
+	SetVariables(processId string, variableChanges map[string]interface{}) (err error)
+}
+
+type DeviceRepo interface {
+	ReadDevice(id string, token string, action drmodel.AuthAction) (result models.Device, err error, errCode int)
+	ReadDeviceType(id string, token string) (result models.DeviceType, err error, errCode int)
 }
 
 func (this *ProcessDeployment) Do(task model.CamundaExternalTask) (modules []model.Module, outputs map[string]interface{}, err error) {
-	modelId := this.getProcessModelId(task)
-	if modelId == "" {
-		return modules, outputs, errors.New("missing process model id")
+	modules, outputs, err = this.do(task)
+	if err != nil && IsRetryable(err) && this.scheduleRetry(task, err) {
+		return modules, outputs, &RetryScheduledError{err: err}
+	}
+	if err != nil {
+		this.reportError(task, err)
+		if this.useBpmnErrorOnFailure(task) && this.throwBpmnError(task, err) {
+			return modules, outputs, &BpmnErrorThrownError{err: err}
+		}
+	}
+	return modules, outputs, err
+}
+
+func (this *ProcessDeployment) do(task model.CamundaExternalTask) (modules []model.Module, outputs map[string]interface{}, err error) {
+	processModels, err := this.getProcessModels(task)
+	if err != nil {
+		return modules, outputs, withErrorCode(ErrCodeInvalidInput, err)
+	}
+	if len(processModels) == 0 {
+		return modules, outputs, withErrorCode(ErrCodeInvalidInput, errors.New("missing process model id"))
 	}
 	userId, err := this.smartServiceRepo.GetInstanceUser(task.ProcessInstanceId)
 	if err != nil {
 		this.libConfig.GetLogger().Error("unable to get instance user", "error", err)
-		return modules, outputs, err
+		return modules, outputs, newTransientError(ErrCodeUpstreamFailed, err)
 	}
 	token, err := this.auth.ExchangeUserToken(userId)
 	if err != nil {
 		this.libConfig.GetLogger().Error("unable to exchange user token", "error", err)
+		return modules, outputs, newTransientError(ErrCodeUpstreamFailed, err)
+	}
+	dryRun, err := this.getDryRun(task)
+	if err != nil {
+		return modules, outputs, withErrorCode(ErrCodeInvalidInput, err)
+	}
+	if dryRun || this.dryRunOnly {
+		outputs, err = this.dryRunProcessModels(token, task, processModels)
 		return modules, outputs, err
 	}
-	deployment, err := this.PrepareRequest(token, modelId)
+	existingModules, err := this.smartServiceRepo.ListExistingModules(task.ProcessInstanceId, model.ModulQuery{TypeFilter: &this.libConfig.CamundaWorkerTopic})
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to list existing modules", "error", err)
+		return modules, outputs, newTransientError(ErrCodeUpstreamFailed, err)
+	}
+	fanOut, err := this.getFanOut(task)
+	if err != nil {
+		return modules, outputs, withErrorCode(ErrCodeInvalidInput, err)
+	}
+	if len(processModels) > 1 || processModels[0].Alias != "" {
+		if fanOut {
+			return modules, outputs, withErrorCode(ErrCodeInvalidInput, errors.New("fan-out is not supported for multiple process models"))
+		}
+		return this.deployProcessModels(task, userId, token, processModels, existingModules)
+	}
+	modelId := processModels[0].ModelId
+	if fanOut {
+		return this.deployFanOut(task, userId, token, modelId, existingModules)
+	}
+
+	//a module with the same id has been registered by a previous execution of the same camunda task
+	//(e.g. after an expired lock or a failed task completion)
+	existingModule, exists, err := this.findReusableModule(token, existingModules, this.getModuleId(task))
 	if err != nil {
-		this.libConfig.GetLogger().Error("unable to prepare process deployment", "error", err)
 		return modules, outputs, err
 	}
+	if exists {
+		return this.reuseExistingModule(task, userId, existingModule)
+	}
+	updateDeploymentId, err := this.getUpdateDeploymentId(task)
+	if err != nil {
+		return modules, outputs, withErrorCode(ErrCodeInvalidInput, err)
+	}
+	if updateDeploymentId != "" {
+		existingModule, exists := findModuleByDeploymentId(existingModules, updateDeploymentId)
+		if !exists {
+			return modules, outputs, withErrorCode(ErrCodeInvalidInput, fmt.Errorf("no existing module found for %v = %v", this.config.WorkerParamPrefix+"update_deployment_id", updateDeploymentId))
+		}
+		return this.updateExistingModule(task, userId, token, existingModule)
+	}
+	module, outputs, err := this.deployProcessModel(task, userId, token, modelId, this.getModuleId(task))
+	if err != nil {
+		return modules, outputs, err
+	}
+	return []model.Module{module}, outputs, nil
+}
+
+func (this *ProcessDeployment) deployProcessModel(task model.CamundaExternalTask, userId string, token auth.Token, modelId string, moduleId string) (module model.Module, outputs map[string]interface{}, err error) {
+	deployment, err := this.prepareDeployment(token, task, modelId)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to prepare process deployment", "error", err)
+		return module, outputs, err
+	}
 
 	err = this.UseVariables(task, &deployment)
 	if err != nil {
 		this.libConfig.GetLogger().Error("unable to use variables", "error", err)
-		return modules, outputs, err
+		return module, outputs, err
+	}
+
+	return this.deployPrepared(task, userId, token, deployment, moduleId)
+}
+
+// deployPrepared deploys a prepared deployment with already applied variables
+func (this *ProcessDeployment) deployPrepared(task model.CamundaExternalTask, userId string, token auth.Token, deployment deploymentmodel.Deployment, moduleId string) (module model.Module, outputs map[string]interface{}, err error) {
+	err = this.validateSelections(token, deployment)
+	if err != nil {
+		this.libConfig.GetLogger().Error("invalid selection", "error", err)
+		return module, outputs, err
 	}
 
 	isFogDeployment, hubId, err := this.IsFogDeployment(token, task, deployment)
 	if err != nil {
 		this.libConfig.GetLogger().Error("unable to use variables", "error", err)
-		return modules, outputs, err
+		return module, outputs, withErrorCode(ErrCodeFogPlacement, err)
 	}
 
 	resultDeployment, err := this.Deploy(token, deployment, true, hubId)
 	if err != nil {
 		this.libConfig.GetLogger().Error("unable to deploy process", "error", err)
-		return modules, outputs, err
+		return module, outputs, err
 	}
 
 	moduleData := this.getModuleData(task)
@@ -98,14 +247,43 @@ func (this *ProcessDeployment) Do(task model.CamundaExternalTask) (modules []mod
 		moduleData["fog_hub"] = hubId
 	}
 
-	deleteEndpoint := this.config.ProcessDeploymentUrl + "/v3/deployments/" + url.PathEscape(resultDeployment.Id)
-	if isFogDeployment {
-		deleteEndpoint = this.config.FogProcessDeploymentUrl + "/deployments/" + url.PathEscape(hubId) + "/" + url.PathEscape(resultDeployment.Id)
+	deleteEndpoint := this.getDeploymentEndpoint(resultDeployment.Id, hubId)
+
+	outputs = getOutputs(resultDeployment.Id, isFogDeployment, hubId)
+	autoSelections, exists, err := this.getAutoSelections(task, deployment)
+	if err != nil {
+		return module, outputs, err
+	}
+	if exists {
+		outputs["auto_selections"] = autoSelections
+	}
+	err = this.setSkippedElements(task, deployment, outputs, moduleData)
+	if err != nil {
+		return module, outputs, err
 	}
 
-	outputs = map[string]interface{}{
-		"process_deployment_id": resultDeployment.Id,
-		"done_event":            deploymentIdToEventId(resultDeployment.Id),
+	this.triggerDoneEvent(userId, resultDeployment.Id, isFogDeployment, hubId)
+
+	return model.Module{
+			Id:               moduleId,
+			ProcesInstanceId: task.ProcessInstanceId,
+			SmartServiceModuleInit: model.SmartServiceModuleInit{
+				DeleteInfo: &model.ModuleDeleteInfo{
+					Url:    deleteEndpoint,
+					UserId: userId,
+				},
+				ModuleType: this.libConfig.CamundaWorkerTopic,
+				ModuleData: moduleData,
+			},
+		},
+		outputs,
+		nil
+}
+
+func getOutputs(deploymentId string, isFogDeployment bool, hubId string) map[string]interface{} {
+	outputs := map[string]interface{}{
+		"process_deployment_id": deploymentId,
+		"done_event":            deploymentIdToEventId(deploymentId),
 	}
 	if isFogDeployment {
 		outputs["is_fog_deployment"] = true
@@ -114,28 +292,149 @@ func (this *ProcessDeployment) Do(task model.CamundaExternalTask) (modules []mod
 		outputs["is_fog_deployment"] = false
 		outputs["fog_hub"] = ""
 	}
+	return outputs
+}
 
-	triggerDoneEvent(this.libConfig, resultDeployment.Id)
+func findModule(modules []model.SmartServiceModule, moduleId string) (result model.SmartServiceModule, exists bool) {
+	for _, module := range modules {
+		if module.Id == moduleId {
+			return module, true
+		}
+	}
+	return result, false
+}
+
+func findModuleByDeploymentId(modules []model.SmartServiceModule, deploymentId string) (result model.SmartServiceModule, exists bool) {
+	for _, module := range modules {
+		if id, ok := module.ModuleData["process_deployment_id"].(string); ok && id == deploymentId {
+			return module, true
+		}
+	}
+	return result, false
+}
+
+// findReusableModule returns the module with the id, if its process deployment still exists.
+// the deployment of a module may be removed by Undo (e.g. after a failed task completion), while the module stays registered;
+// such modules are not reusable and have to be deployed again.
+func (this *ProcessDeployment) findReusableModule(token auth.Token, modules []model.SmartServiceModule, moduleId string) (result model.SmartServiceModule, exists bool, err error) {
+	result, exists = findModule(modules, moduleId)
+	if !exists {
+		return result, false, nil
+	}
+	deploymentId, ok := result.ModuleData["process_deployment_id"].(string)
+	if !ok || deploymentId == "" {
+		return result, false, fmt.Errorf("existing module %v is missing process_deployment_id in module data", result.Id)
+	}
+	isFogDeployment, _ := result.ModuleData["is_fog_deployment"].(bool)
+	hubId, _ := result.ModuleData["fog_hub"].(string)
+	deploymentExists, err := this.deploymentExists(token, deploymentId, isFogDeployment, hubId)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to check existing process deployment", "moduleId", result.Id, "deploymentId", deploymentId, "error", err)
+		return result, false, err
+	}
+	if !deploymentExists {
+		this.libConfig.GetLogger().Warn("process deployment of existing module is missing; deploy again", "moduleId", result.Id, "deploymentId", deploymentId)
+	}
+	return result, deploymentExists, nil
+}
+
+func (this *ProcessDeployment) reuseExistingModule(task model.CamundaExternalTask, userId string, module model.SmartServiceModule) (modules []model.Module, outputs map[string]interface{}, err error) {
+	deploymentId, ok := module.ModuleData["process_deployment_id"].(string)
+	if !ok || deploymentId == "" {
+		return modules, outputs, fmt.Errorf("existing module %v is missing process_deployment_id in module data", module.Id)
+	}
+	isFogDeployment, _ := module.ModuleData["is_fog_deployment"].(bool)
+	hubId, _ := module.ModuleData["fog_hub"].(string)
+	this.libConfig.GetLogger().Info("reuse existing process deployment", "moduleId", module.Id, "deploymentId", deploymentId)
+
+	this.triggerDoneEvent(userId, deploymentId, isFogDeployment, hubId)
 
 	return []model.Module{{
-			Id:               this.getModuleId(task),
+			Id:                     module.Id,
+			ProcesInstanceId:       task.ProcessInstanceId,
+			SmartServiceModuleInit: module.SmartServiceModuleInit,
+		}},
+		getOutputs(deploymentId, isFogDeployment, hubId),
+		nil
+}
+
+// updateExistingModule replaces the deployment of an existing module with a version that uses the current task variables.
+// the module keeps its id and DeleteInfo.
+func (this *ProcessDeployment) updateExistingModule(task model.CamundaExternalTask, userId string, token auth.Token, module model.SmartServiceModule) (modules []model.Module, outputs map[string]interface{}, err error) {
+	deploymentId, ok := module.ModuleData["process_deployment_id"].(string)
+	if !ok || deploymentId == "" {
+		return modules, outputs, fmt.Errorf("existing module %v is missing process_deployment_id in module data", module.Id)
+	}
+	isFogDeployment, _ := module.ModuleData["is_fog_deployment"].(bool)
+	hubId, _ := module.ModuleData["fog_hub"].(string)
+	if isFogDeployment && hubId == "" {
+		return modules, outputs, fmt.Errorf("existing module %v is missing fog_hub in module data", module.Id)
+	}
+
+	deployment, err := this.GetDeployment(token, deploymentId, hubId)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to get process deployment", "error", err)
+		return modules, outputs, err
+	}
+
+	err = this.UseVariables(task, &deployment)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to use variables", "error", err)
+		return modules, outputs, err
+	}
+
+	err = this.validateSelections(token, deployment)
+	if err != nil {
+		this.libConfig.GetLogger().Error("invalid selection", "error", err)
+		return modules, outputs, err
+	}
+
+	resultDeployment, err := this.UpdateDeployment(token, deployment, true, hubId)
+	if err != nil {
+		this.libConfig.GetLogger().Error("unable to update process deployment", "error", err)
+		return modules, outputs, err
+	}
+
+	moduleData := module.ModuleData
+	if moduleData == nil {
+		moduleData = map[string]interface{}{}
+	}
+	for key, value := range this.getModuleData(task) {
+		moduleData[key] = value
+	}
+	moduleData["process_deployment_name"] = resultDeployment.Name
+	moduleData["process_deployment_updated"] = true
+
+	outputs = getOutputs(deploymentId, isFogDeployment, hubId)
+	err = this.setSkippedElements(task, deployment, outputs, moduleData)
+	if err != nil {
+		return modules, outputs, err
+	}
+
+	this.triggerDoneEvent(userId, deploymentId, isFogDeployment, hubId)
+
+	return []model.Module{{
+			Id:               module.Id,
 			ProcesInstanceId: task.ProcessInstanceId,
 			SmartServiceModuleInit: model.SmartServiceModuleInit{
-				DeleteInfo: &model.ModuleDeleteInfo{
-					Url:    deleteEndpoint,
-					UserId: userId,
-				},
-				ModuleType: this.libConfig.CamundaWorkerTopic,
+				DeleteInfo: module.DeleteInfo,
+				ModuleType: module.ModuleType,
 				ModuleData: moduleData,
+				Keys:       module.Keys,
 			},
 		}},
 		outputs,
-		err
+		nil
 }
 
 func (this *ProcessDeployment) Undo(modules []model.Module, reason error) {
 	this.libConfig.GetLogger().Debug("undo", "reason", reason)
 	for _, module := range modules {
+		if updated, _ := module.ModuleData["process_deployment_updated"].(bool); updated {
+			//the previous version of an updated deployment can not be restored; keep the update
+			this.libConfig.GetLogger().Warn("skip undo of updated process deployment", "moduleId", module.Id)
+			continue
+		}
 		if module.DeleteInfo != nil {
 			err := this.smartServiceRepo.UseModuleDeleteInfo(*module.DeleteInfo)
 			if err != nil {
@@ -146,50 +445,90 @@ func (this *ProcessDeployment) Undo(modules []model.Module, reason error) {
 }
 
 func (this *ProcessDeployment) UseVariables(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment) error {
-	name := this.getProcessName(task)
+	name, err := this.getProcessName(task)
+	if err != nil {
+		return withErrorCode(ErrCodeInvalidInput, err)
+	}
 	if name != "" {
 		deployment.Name = name
 	}
-	err := this.setStartParameter(task, deployment)
+	err = this.setStartParameter(task, deployment)
 	if err != nil {
-		return err
+		return withErrorCode(ErrCodeInvalidInput, err)
 	}
 	err = this.setIncidentHandling(task, deployment)
 	if err != nil {
-		return err
+		return withErrorCode(ErrCodeInvalidInput, err)
 	}
 	handler := []func(task model.CamundaExternalTask, element *deploymentmodel.Element) error{
 		this.setSelection,
 		this.setParameter,
 		this.setConditionalEventVariables,
+		this.setConditionalEventConfig,
 		this.setMsgEventConfig,
+		this.setNotification,
 		this.setTime,
 	}
+	elementErrors := []ElementError{}
 	for i, element := range deployment.Elements {
 		for _, h := range handler {
 			err := h(task, &element)
-			if err != nil {
+			if err != nil && IsRetryable(err) {
 				return err
 			}
+			if err != nil {
+				elementErrors = append(elementErrors, ElementError{BpmnId: element.BpmnId, Name: element.Name, Message: err.Error()})
+			}
 		}
 		deployment.Elements[i] = element
 	}
-	return nil
+	autoSelectErrors, err := this.autoSelect(task, deployment)
+	if err != nil {
+		return err
+	}
+	elementErrors = append(elementErrors, autoSelectErrors...)
+	elementErrors = append(elementErrors, this.applySelectionRefs(task, deployment)...)
+	elementErrors = append(elementErrors, checkGroupDevices(deployment)...)
+	return newElementErrors(elementErrors)
+}
+
+// getInstanceUserToken returns a token of the smart-service instance user, for requests in steps without token (e.g. UseVariables)
+func (this *ProcessDeployment) getInstanceUserToken(task model.CamundaExternalTask) (token auth.Token, err error) {
+	userId, err := this.smartServiceRepo.GetInstanceUser(task.ProcessInstanceId)
+	if err != nil {
+		return token, newTransientError(ErrCodeUpstreamFailed, err)
+	}
+	token, err = this.auth.ExchangeUserToken(userId)
+	if err != nil {
+		return token, newTransientError(ErrCodeUpstreamFailed, err)
+	}
+	return token, nil
 }
 
 func (this *ProcessDeployment) getModuleId(task model.CamundaExternalTask) string {
 	return task.ProcessInstanceId + "." + task.Id
 }
 
+// IsFogDeployment decides if the deployment is placed in the fog and selects the hub.
+// fog_hub_id forces the hub; if it can not be used, an error is returned instead of a cloud deployment.
+// otherwise, with prefer_fog_deployment, the fog_placement_strategy selects one of the hubs that contain every device.
 func (this *ProcessDeployment) IsFogDeployment(token auth.Token, task model.CamundaExternalTask, deployment deploymentmodel.Deployment) (isFogDeployment bool, hubId string, err error) {
+	forcedHubId, err := this.getFogHubId(task)
+	if err != nil {
+		return false, "", err
+	}
 	preferFogDeployment, err := this.getPreferFogDeployment(task)
 	if err != nil {
 		return false, "", err
 	}
-	if !preferFogDeployment {
+	if !preferFogDeployment && forcedHubId == "" {
 		this.libConfig.GetLogger().Debug("IsFogDeployment: preferFogDeployment == false")
 		return false, "", nil
 	}
+	strategy, err := this.getFogPlacementStrategy(task)
+	if err != nil {
+		return false, "", err
+	}
 
 	devices := []string{}
 	groups := []string{}
@@ -232,10 +571,16 @@ func (this *ProcessDeployment) IsFogDeployment(token auth.Token, task model.Camu
 		}
 	}
 	if !this.config.AllowMsgEventsInFogProcesses && usesEvents {
+		if forcedHubId != "" {
+			return false, "", fmt.Errorf("unable to use fog hub %v: message events are not allowed in fog processes", forcedHubId)
+		}
 		this.libConfig.GetLogger().Debug("IsFogDeployment: usesEvents == true && AllowMsgEventsInFogProcesses == false")
 		return false, "", nil
 	}
 	if !this.config.AllowImportsInFogProcesses && len(imports) > 0 {
+		if forcedHubId != "" {
+			return false, "", fmt.Errorf("unable to use fog hub %v: imports are not allowed in fog processes", forcedHubId)
+		}
 		this.libConfig.GetLogger().Debug("IsFogDeployment: len(imports) > 0 && AllowImportsInFogProcesses == false")
 		return false, "", nil
 	}
@@ -252,30 +597,74 @@ func (this *ProcessDeployment) IsFogDeployment(token auth.Token, task model.Camu
 		return false, "", err
 	}
 	if len(devices) == 0 {
+		if forcedHubId != "" {
+			return false, "", fmt.Errorf("unable to use fog hub %v: process deployments without devices can not be run in fog", forcedHubId)
+		}
 		this.libConfig.GetLogger().Warn("process deployments without devices wont be run in fog")
 		return false, "", nil
 	}
+	pureDevices := pureIds(devices)
 	missingDevices := map[string]string{}
+	candidates := []Hub{}
 	for _, network := range networks {
 		networkIndex := map[string]bool{}
-		for _, id := range network.DeviceIds {
+		for _, id := range this.hubDeviceIds(network) {
 			networkIndex[id] = true
 		}
 		missingDeviceInNetwork := false
-		for _, id := range devices {
-			pureId, _ := idmodifier.SplitModifier(id)
-			if !networkIndex[pureId] && !networkIndex[id] {
+		for _, pureId := range pureDevices {
+			if !networkIndex[pureId] {
 				missingDeviceInNetwork = true
-				missingDevices[network.Name] = pureId
+				missingDevices[network.Id] = pureId
 				break
 			}
 		}
 		if !missingDeviceInNetwork {
-			return true, network.Id, nil
+			candidates = append(candidates, network)
+		}
+	}
+	if forcedHubId != "" {
+		if slices.ContainsFunc(candidates, func(hub Hub) bool { return hub.Id == forcedHubId }) {
+			return true, forcedHubId, nil
+		}
+		for _, network := range networks {
+			if network.Id == forcedHubId {
+				return false, "", fmt.Errorf("unable to use fog hub %v: device %v is not in the fog hub", forcedHubId, missingDevices[network.Id])
+			}
+		}
+		return false, "", fmt.Errorf("unknown fog hub %v", forcedHubId)
+	}
+	if len(candidates) == 0 {
+		this.libConfig.GetLogger().Debug("IsFogDeployment: missingDeviceInNetwork", "missingDevices", fmt.Sprintf("%#v", missingDevices))
+		return false, "", nil
+	}
+	hubId, err = this.selectFogHub(token, task, strategy, candidates)
+	if err != nil {
+		return false, "", err
+	}
+	return true, hubId, nil
+}
+
+// pureIds returns the ids without id modifiers; unknown modifiers are stripped like known ones
+func pureIds(ids []string) (result []string) {
+	for _, id := range ids {
+		result = append(result, idmodifier.PureId(id))
+	}
+	return result
+}
+
+// hubDeviceIds returns the device ids of the hub without id modifiers;
+// ids of the fog sync service that can not be parsed are logged and skipped
+func (this *ProcessDeployment) hubDeviceIds(hub Hub) (result []string) {
+	for _, id := range hub.DeviceIds {
+		pureId, _, err := idmodifier.SplitModifier(id)
+		if err != nil {
+			this.libConfig.GetLogger().Warn("ignore invalid device id of fog hub", "hub", hub.Id, "device", id, "error", err)
+			continue
 		}
+		result = append(result, pureId)
 	}
-	this.libConfig.GetLogger().Debug("IsFogDeployment: missingDeviceInNetwork", "missingDevices", fmt.Sprintf("%#v", missingDevices))
-	return false, "", nil
+	return result
 }
 
 type Hub struct {
@@ -303,14 +692,14 @@ func (this *ProcessDeployment) GetFogNetworks(token auth.Token) (result []Hub, e
 	resp, err := client.Do(req)
 	if err != nil {
 		debug.PrintStack()
-		return result, err
+		return result, newTransientError(ErrCodeUpstreamFailed, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
 		debug.PrintStack()
 		temp, _ := io.ReadAll(resp.Body)
-		return result, fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp))
+		return result, newHttpError(ErrCodeUpstreamFailed, resp.StatusCode, string(temp), fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp)))
 	}
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	_, _ = io.ReadAll(resp.Body)
@@ -341,14 +730,14 @@ func (this *ProcessDeployment) GetGroup(token auth.Token, id string) (result Dev
 	resp, err := client.Do(req)
 	if err != nil {
 		debug.PrintStack()
-		return result, err
+		return result, newTransientError(ErrCodeUpstreamFailed, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
 		debug.PrintStack()
 		temp, _ := io.ReadAll(resp.Body)
-		return result, fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp))
+		return result, newHttpError(ErrCodeUpstreamFailed, resp.StatusCode, string(temp), fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp)))
 	}
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	_, _ = io.ReadAll(resp.Body)
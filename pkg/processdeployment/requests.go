@@ -25,14 +25,15 @@ import (
 	"net/http"
 	"net/url"
 	"runtime/debug"
+	"strconv"
 	"time"
 
 	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
 )
 
-func (this *ProcessDeployment) PrepareRequest(token auth.Token, processId string) (deployment deploymentmodel.Deployment, err error) {
-	req, err := http.NewRequest("GET", this.config.ProcessDeploymentUrl+"/v3/prepared-deployments/"+url.PathEscape(processId)+"?with_options=false", nil)
+func (this *ProcessDeployment) PrepareRequest(token auth.Token, processId string, withOptions bool) (deployment deploymentmodel.Deployment, err error) {
+	req, err := http.NewRequest("GET", this.config.ProcessDeploymentUrl+"/v3/prepared-deployments/"+url.PathEscape(processId)+"?with_options="+strconv.FormatBool(withOptions), nil)
 	if err != nil {
 		return deployment, err
 	}
@@ -40,12 +41,12 @@ func (this *ProcessDeployment) PrepareRequest(token auth.Token, processId string
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return deployment, err
+		return deployment, newTransientError(ErrCodePrepareFailed, err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
 		temp, _ := io.ReadAll(resp.Body)
-		err = errors.New(string(temp))
+		err = newHttpError(ErrCodePrepareFailed, resp.StatusCode, string(temp), errors.New(string(temp)))
 		return deployment, err
 	}
 	err = json.NewDecoder(resp.Body).Decode(&deployment)
@@ -53,6 +54,25 @@ func (this *ProcessDeployment) PrepareRequest(token auth.Token, processId string
 }
 
 func (this *ProcessDeployment) Deploy(token auth.Token, deployment deploymentmodel.Deployment, allowMissingServiceSelection bool, hubId string) (result deploymentmodel.Deployment, err error) {
+	endpoint := this.config.ProcessDeploymentUrl + "/v3/deployments"
+	if hubId != "" {
+		endpoint = this.config.FogProcessDeploymentUrl + "/deployments/" + url.PathEscape(hubId)
+	}
+	return this.sendDeployment(token, "POST", endpoint, deployment, allowMissingServiceSelection)
+}
+
+func (this *ProcessDeployment) UpdateDeployment(token auth.Token, deployment deploymentmodel.Deployment, allowMissingServiceSelection bool, hubId string) (result deploymentmodel.Deployment, err error) {
+	return this.sendDeployment(token, "PUT", this.getDeploymentEndpoint(deployment.Id, hubId), deployment, allowMissingServiceSelection)
+}
+
+func (this *ProcessDeployment) getDeploymentEndpoint(deploymentId string, hubId string) string {
+	if hubId != "" {
+		return this.config.FogProcessDeploymentUrl + "/deployments/" + url.PathEscape(hubId) + "/" + url.PathEscape(deploymentId)
+	}
+	return this.config.ProcessDeploymentUrl + "/v3/deployments/" + url.PathEscape(deploymentId)
+}
+
+func (this *ProcessDeployment) sendDeployment(token auth.Token, method string, endpoint string, deployment deploymentmodel.Deployment, allowMissingServiceSelection bool) (result deploymentmodel.Deployment, err error) {
 	queryStr := ""
 	query := url.Values{}
 	source := this.config.ProcessDeploymentSource
@@ -83,24 +103,40 @@ func (this *ProcessDeployment) Deploy(token auth.Token, deployment deploymentmod
 		this.libConfig.GetLogger().Error("error in ProcessDeployment.Deploy", "error", err, "stack", string(debug.Stack()))
 		return result, err
 	}
-	endpoint := this.config.ProcessDeploymentUrl + "/v3/deployments" + queryStr
-	if hubId != "" {
-		endpoint = this.config.FogProcessDeploymentUrl + "/deployments/" + url.PathEscape(hubId) + queryStr
-	}
-	req, err := http.NewRequest("POST", endpoint, body)
+	req, err := http.NewRequest(method, endpoint+queryStr, body)
 	if err != nil {
 		return result, err
 	}
 	req.Header.Set("Authorization", token.Jwt())
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return result, newTransientError(ErrCodeDeployFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		temp, _ := io.ReadAll(resp.Body)
+		err = newHttpError(ErrCodeDeployFailed, resp.StatusCode, string(temp), errors.New(string(temp)))
+		return result, err
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
+func (this *ProcessDeployment) GetDeployment(token auth.Token, deploymentId string, hubId string) (result deploymentmodel.Deployment, err error) {
+	req, err := http.NewRequest("GET", this.getDeploymentEndpoint(deploymentId, hubId), nil)
 	if err != nil {
 		return result, err
 	}
+	req.Header.Set("Authorization", token.Jwt())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return result, newTransientError(ErrCodeUpstreamFailed, err)
+	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
 		temp, _ := io.ReadAll(resp.Body)
-		err = errors.New(string(temp))
+		err = newHttpError(ErrCodeUpstreamFailed, resp.StatusCode, string(temp), errors.New(string(temp)))
 		return result, err
 	}
 	err = json.NewDecoder(resp.Body).Decode(&result)
@@ -136,6 +172,29 @@ func (this *ProcessDeployment) CheckDeployment(token auth.Token, deploymentId st
 	return resp.StatusCode, nil
 }
 
+// deploymentExists returns false if the process deployment is unknown or, in the fog, marked for delete
+func (this *ProcessDeployment) deploymentExists(token auth.Token, deploymentId string, isFogDeployment bool, hubId string) (bool, error) {
+	if isFogDeployment {
+		metadata, err, code := this.GetFogSyncMetadata(token, hubId, deploymentId)
+		if err != nil {
+			return false, newHttpError(ErrCodeUpstreamFailed, code, err.Error(), fmt.Errorf("unable to check fog deployment %v: %w", deploymentId, err))
+		}
+		return len(metadata) > 0 && !metadata[0].MarkedForDelete, nil
+	}
+	code, err := this.CheckDeployment(token, deploymentId)
+	if err != nil {
+		return false, newTransientError(ErrCodeUpstreamFailed, err)
+	}
+	switch code {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, newHttpError(ErrCodeUpstreamFailed, code, "", fmt.Errorf("unexpected deployment check status-code %v", code))
+	}
+}
+
 func (this *ProcessDeployment) CheckFogDeployment(token auth.Token, hubId string, deploymentId string) (error, error) {
 	metadata, err, _ := this.GetFogSyncMetadata(token, hubId, deploymentId)
 	if err != nil {
@@ -153,8 +212,13 @@ func (this *ProcessDeployment) CheckFogDeployment(token auth.Token, hubId string
 	return nil, nil
 }
 
+// GetFogSyncMetadata returns the sync metadata of the deployment or, if deploymentId is empty, of all deployments of the hub
 func (this *ProcessDeployment) GetFogSyncMetadata(token auth.Token, hubId string, deploymentId string) (result []DeploymentMetadata, err error, code int) {
-	req, err := http.NewRequest("GET", this.config.FogProcessSyncUrl+"/metadata/"+url.PathEscape(hubId)+"?deployment_id="+url.QueryEscape(deploymentId), nil)
+	endpoint := this.config.FogProcessSyncUrl + "/metadata/" + url.PathEscape(hubId)
+	if deploymentId != "" {
+		endpoint = endpoint + "?deployment_id=" + url.QueryEscape(deploymentId)
+	}
+	req, err := http.NewRequest("GET", endpoint, nil)
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
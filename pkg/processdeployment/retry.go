@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// RetryScheduledError is returned by Do, if the task failed with a retryable error and camunda will retry it.
+// the error must not be handled as instance error (see IsRetryScheduled).
+type RetryScheduledError struct {
+	err error
+}
+
+func (this *RetryScheduledError) Error() string {
+	return "retry scheduled: " + this.err.Error()
+}
+
+func (this *RetryScheduledError) Unwrap() error {
+	return this.err
+}
+
+func IsRetryScheduled(err error) bool {
+	var retryScheduledError *RetryScheduledError
+	return errors.As(err, &retryScheduledError)
+}
+
+// BpmnErrorThrownError is returned by Do, if the task failed with a permanent error that has been thrown as bpmn error.
+// the process instance continues on the bpmn error path; the error must not be handled as instance error (see IsBpmnErrorThrown).
+type BpmnErrorThrownError struct {
+	err error
+}
+
+func (this *BpmnErrorThrownError) Error() string {
+	return "bpmn error thrown: " + this.err.Error()
+}
+
+func (this *BpmnErrorThrownError) Unwrap() error {
+	return this.err
+}
+
+func IsBpmnErrorThrown(err error) bool {
+	var bpmnErrorThrownError *BpmnErrorThrownError
+	return errors.As(err, &bpmnErrorThrownError)
+}
+
+// useBpmnErrorOnFailure returns true if the model handles permanent errors with error boundary events ({{WorkerParamPrefix}}bpmn_error_on_failure).
+// by default, errors are reported as instance error and the instance is stopped.
+func (this *ProcessDeployment) useBpmnErrorOnFailure(task model.CamundaExternalTask) bool {
+	parameterName := this.config.WorkerParamPrefix + "bpmn_error_on_failure"
+	result, _, err := getBoolVariable(task, parameterName)
+	if err != nil {
+		this.libConfig.GetLogger().Warn("ignore invalid "+parameterName, "error", err)
+		return false
+	}
+	return result
+}
+
+// throwBpmnError reports the error to camunda as bpmn error with DeploymentError.Code as error code
+// and the DeploymentError as variable {{CamundaWorkerTopic}}_error.
+// returns false if the bpmn error could not be reported.
+func (this *ProcessDeployment) throwBpmnError(task model.CamundaExternalTask, err error) bool {
+	deploymentError := toDeploymentError(err)
+	errorJson, marshalErr := json.Marshal(deploymentError)
+	if marshalErr != nil {
+		this.libConfig.GetLogger().Error("unable to marshal deployment error", "error", marshalErr)
+		return false
+	}
+	body, marshalErr := json.Marshal(map[string]interface{}{
+		"workerId":     this.libConfig.CamundaWorkerId,
+		"errorCode":    deploymentError.Code,
+		"errorMessage": deploymentError.Message,
+		"variables": map[string]model.CamundaVariable{
+			this.libConfig.CamundaWorkerTopic + "_error": {Type: "String", Value: string(errorJson)},
+		},
+	})
+	if marshalErr != nil {
+		this.libConfig.GetLogger().Error("unable to marshal bpmn error", "error", marshalErr)
+		return false
+	}
+	client := http.Client{Timeout: DefaultTimeout}
+	resp, reqErr := client.Post(this.libConfig.CamundaUrl+"/engine-rest/external-task/"+url.PathEscape(task.Id)+"/bpmnError", "application/json", bytes.NewBuffer(body))
+	if reqErr != nil {
+		this.libConfig.GetLogger().Error("unable to throw bpmn error", "error", reqErr)
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		temp, _ := io.ReadAll(resp.Body)
+		this.libConfig.GetLogger().Error("unable to throw bpmn error", "statuscode", resp.StatusCode, "response", string(temp))
+		return false
+	}
+	return true
+}
+
+// scheduleRetry reports the failure to camunda, which unlocks the task and retries it after config.CamundaTaskRetryTimeout.
+// returns false if no retries are left or the failure could not be reported.
+func (this *ProcessDeployment) scheduleRetry(task model.CamundaExternalTask, err error) bool {
+	//camunda sends retries == null until the first failure is reported
+	retries := this.config.CamundaTaskRetries
+	if task.Error != "" {
+		retries = task.Retries - 1
+	}
+	if retries <= 0 {
+		return false
+	}
+	reportErr := this.sendTaskFailure(task, err, retries)
+	if reportErr != nil {
+		this.libConfig.GetLogger().Error("unable to report task failure", "error", reportErr)
+		return false
+	}
+	this.libConfig.GetLogger().Warn("retryable task failure", "taskId", task.Id, "retries", retries, "error", err)
+	return true
+}
+
+func (this *ProcessDeployment) sendTaskFailure(task model.CamundaExternalTask, err error, retries int64) error {
+	body, marshalErr := json.Marshal(map[string]interface{}{
+		"workerId":     this.libConfig.CamundaWorkerId,
+		"errorMessage": err.Error(),
+		"retries":      retries,
+		"retryTimeout": this.retryTimeout.Milliseconds(),
+	})
+	if marshalErr != nil {
+		return marshalErr
+	}
+	client := http.Client{Timeout: DefaultTimeout}
+	resp, reqErr := client.Post(this.libConfig.CamundaUrl+"/engine-rest/external-task/"+url.PathEscape(task.Id)+"/failure", "application/json", bytes.NewBuffer(body))
+	if reqErr != nil {
+		return reqErr
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		temp, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"fmt"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// getSelectionRef returns the referenced BpmnId, if the element has no own selection variable but a selection_ref
+func (this *ProcessDeployment) getSelectionRef(task model.CamundaExternalTask, element *deploymentmodel.Element) (ref string, exists bool, err error) {
+	_, ownSelection := task.Variables[this.config.WorkerParamPrefix+element.BpmnId+".selection"]
+	if ownSelection {
+		return "", false, nil
+	}
+	ref, exists, err = getStringVariable(task, this.config.WorkerParamPrefix+element.BpmnId+".selection_ref")
+	if err != nil || !exists {
+		return "", false, err
+	}
+	if ref == "" {
+		return "", false, fmt.Errorf("empty selection_ref for %v", element.BpmnId)
+	}
+	return ref, true, nil
+}
+
+// applySelectionRefs copies the selected values of the referenced elements to every element with a selection_ref
+func (this *ProcessDeployment) applySelectionRefs(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment) (result []ElementError) {
+	index := map[string]int{}
+	for i, element := range deployment.Elements {
+		index[element.BpmnId] = i
+	}
+	for i, element := range deployment.Elements {
+		err := this.applySelectionRef(task, deployment, index, i, map[string]bool{})
+		if err != nil {
+			result = append(result, ElementError{BpmnId: element.BpmnId, Name: element.Name, Message: err.Error()})
+		}
+	}
+	return result
+}
+
+func (this *ProcessDeployment) applySelectionRef(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment, index map[string]int, i int, visited map[string]bool) error {
+	element := &deployment.Elements[i]
+	selection := getElementSelection(element)
+	if selection == nil {
+		return nil
+	}
+	ref, exists, err := this.getSelectionRef(task, element)
+	if err != nil || !exists {
+		return err
+	}
+	if visited[element.BpmnId] {
+		return fmt.Errorf("cyclic selection_ref for %v", element.BpmnId)
+	}
+	visited[element.BpmnId] = true
+	refIndex, ok := index[ref]
+	if !ok {
+		return fmt.Errorf("unknown element %v in selection_ref of %v", ref, element.BpmnId)
+	}
+	err = this.applySelectionRef(task, deployment, index, refIndex, visited)
+	if err != nil {
+		return err
+	}
+	refSelection := getElementSelection(&deployment.Elements[refIndex])
+	if refSelection == nil {
+		return fmt.Errorf("element %v in selection_ref of %v has no selection", ref, element.BpmnId)
+	}
+	if !hasSelectedValue(refSelection) {
+		optional, err := this.isOptionalElement(task, element)
+		if err != nil {
+			return err
+		}
+		if !optional {
+			return fmt.Errorf("element %v in selection_ref of %v has no selection", ref, element.BpmnId)
+		}
+	}
+	selection.SelectedDeviceId = refSelection.SelectedDeviceId
+	selection.SelectedServiceId = refSelection.SelectedServiceId
+	selection.SelectedDeviceGroupId = refSelection.SelectedDeviceGroupId
+	selection.SelectedImportId = refSelection.SelectedImportId
+	selection.SelectedGenericEventSource = refSelection.SelectedGenericEventSource
+	selection.SelectedPath = refSelection.SelectedPath
+	return nil
+}
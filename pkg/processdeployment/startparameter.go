@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// startParameterDateLayouts lists the accepted values of start parameters with the type date
+var startParameterDateLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly, "02/01/2006"}
+
+// setStartParameter sets the default values of the start parameters.
+// values are taken from start_parameter.default.<id> or, if not set and start_parameter.use_instance_variables is true,
+// from the same-named smart-service instance variable.
+// values are validated against the parameter type; parameters with the property required=true need a value.
+// unknown start_parameter.default.<id> variables are rejected, except in multi-model deployments, where un-namespaced defaults may belong to other models.
+func (this *ProcessDeployment) setStartParameter(task model.CamundaExternalTask, deployment *deploymentmodel.Deployment) error {
+	prefix := this.config.WorkerParamPrefix + "start_parameter.default."
+	useInstanceVariables, _, err := getBoolVariable(task, this.config.WorkerParamPrefix+"start_parameter.use_instance_variables")
+	if err != nil {
+		return err
+	}
+	unknown := []string{}
+	for key := range task.Variables {
+		if strings.HasPrefix(key, prefix) {
+			paramName := strings.TrimPrefix(key, prefix)
+			if !slices.ContainsFunc(deployment.StartParameter, func(param deploymentmodel.ProcessStartParameter) bool {
+				return param.Id == paramName
+			}) {
+				unknown = append(unknown, paramName)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		if _, isMultiModel := task.Variables[this.config.WorkerParamPrefix+"process_model_ids"]; isMultiModel {
+			this.libConfig.GetLogger().Warn("ignore start parameter defaults unknown to the process model of a multi-model deployment", "processInstanceId", task.ProcessInstanceId, "startParameter", strings.Join(unknown, ", "))
+		} else {
+			return fmt.Errorf("unknown start parameter %v", strings.Join(unknown, ", "))
+		}
+	}
+	var instanceVariables map[string]interface{}
+	for i, param := range deployment.StartParameter {
+		value, exists, err := getJsonStringVariable(task, prefix+param.Id)
+		if err != nil {
+			return err
+		}
+		if !exists && useInstanceVariables {
+			if instanceVariables == nil {
+				instanceVariables, err = this.smartServiceRepo.GetVariables(task.ProcessInstanceId)
+				if err != nil {
+					return newTransientError(ErrCodeUpstreamFailed, err)
+				}
+				if instanceVariables == nil {
+					instanceVariables = map[string]interface{}{}
+				}
+			}
+			var instanceValue interface{}
+			instanceValue, exists = instanceVariables[param.Id]
+			if exists {
+				value, err = toStartParameterValue(instanceValue)
+				if err != nil {
+					return fmt.Errorf("unexpected value in instance variable %v: %w", param.Id, err)
+				}
+			}
+		}
+		if exists {
+			err = validateStartParameterValue(param.Type, value)
+			if err != nil {
+				return fmt.Errorf("invalid value for start parameter %v: %w", param.Id, err)
+			}
+			param.Default = value
+		}
+		if param.Properties["required"] == "true" && param.Default == "" {
+			return fmt.Errorf("missing value for required start parameter %v", param.Id)
+		}
+		deployment.StartParameter[i] = param
+	}
+	return nil
+}
+
+// toStartParameterValue uses strings as is and json encodes all other values
+func toStartParameterValue(value interface{}) (string, error) {
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	temp, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(temp), nil
+}
+
+// validateStartParameterValue checks the value against the camunda form-field type of the start parameter.
+// string, enum and unknown types are not validated.
+func validateStartParameterValue(paramType string, value string) (err error) {
+	switch strings.ToLower(paramType) {
+	case "long", "integer", "int", "short":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "double", "float", "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "boolean", "bool":
+		_, err = strconv.ParseBool(value)
+	case "date":
+		for _, layout := range startParameterDateLayouts {
+			if _, err = time.Parse(layout, value); err == nil {
+				return nil
+			}
+		}
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("expected %v, got %v", paramType, value)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" //timezones are loaded without system tzdata
+
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+const (
+	TimeEventTypeDate     = "timeDate"
+	TimeEventTypeDuration = "timeDuration"
+	TimeEventTypeCycle    = "timeCycle"
+)
+
+var isoDurationPattern = regexp.MustCompile(`^P(\d+Y)?(\d+M)?(\d+W)?(\d+D)?(T(\d+H)?(\d+M)?(\d+(\.\d+)?S)?)?$`)
+var secondsPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+var cronFieldPattern = regexp.MustCompile(`^[0-9A-Za-z*?/,\-#]+$`)
+
+var localDateTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// getTimezone returns the location of {{BpmnId}}.timezone or timezone and the name of the used variable; UTC and "" if none is set
+func (this *ProcessDeployment) getTimezone(task model.CamundaExternalTask, bpmnId string) (location *time.Location, variable string, err error) {
+	for _, name := range []string{this.config.WorkerParamPrefix + bpmnId + ".timezone", this.config.WorkerParamPrefix + "timezone"} {
+		timezone, exists, err := getStringVariable(task, name)
+		if err != nil {
+			return nil, "", err
+		}
+		if exists && timezone != "" {
+			location, err := time.LoadLocation(timezone)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid %v: %w", name, err)
+			}
+			return location, name, nil
+		}
+	}
+	return time.UTC, "", nil
+}
+
+// convertTimeEventValue converts durations ("15m"), plain seconds, cron expressions and dates into the iso-8601 value expected by the time-event type.
+// local date-times of dates and iso-8601 cycles are interpreted in location. values of unknown time-event types are used as is.
+func convertTimeEventValue(eventType string, value string, location *time.Location) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("empty time value")
+	}
+	switch eventType {
+	case TimeEventTypeDuration:
+		return toIsoDuration(value)
+	case TimeEventTypeCycle:
+		return toTimeCycle(value, location)
+	case TimeEventTypeDate:
+		return toIsoDate(value, location)
+	default:
+		return value, nil
+	}
+}
+
+func toIsoDuration(value string) (string, error) {
+	if isIsoDuration(value) {
+		return value, nil
+	}
+	var duration time.Duration
+	var err error
+	if secondsPattern.MatchString(value) {
+		var seconds float64
+		seconds, err = strconv.ParseFloat(value, 64)
+		duration = time.Duration(seconds * float64(time.Second))
+	} else {
+		duration, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid duration %v: expected iso-8601 duration (PT15M), duration (15m) or seconds", value)
+	}
+	if duration <= 0 {
+		return "", fmt.Errorf("invalid duration %v: duration must be positive", value)
+	}
+	return formatIsoDuration(duration), nil
+}
+
+func isIsoDuration(value string) bool {
+	return isoDurationPattern.MatchString(value) && value != "P" && !strings.HasSuffix(value, "T")
+}
+
+func formatIsoDuration(duration time.Duration) string {
+	hours := int64(duration / time.Hour)
+	minutes := int64((duration % time.Hour) / time.Minute)
+	seconds := float64(duration%time.Minute) / float64(time.Second)
+	result := "PT"
+	if hours > 0 {
+		result += strconv.FormatInt(hours, 10) + "H"
+	}
+	if minutes > 0 {
+		result += strconv.FormatInt(minutes, 10) + "M"
+	}
+	if seconds > 0 {
+		result += strconv.FormatFloat(math.Round(seconds*1000)/1000, 'f', -1, 64) + "S"
+	}
+	return result
+}
+
+func toTimeCycle(value string, location *time.Location) (string, error) {
+	if isIsoCycle(value) {
+		return toIsoCycle(value, location)
+	}
+	if isCronExpression(value) {
+		return toQuartzCron(strings.Fields(value))
+	}
+	duration, err := toIsoDuration(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid time cycle %v: expected iso-8601 cycle (R/PT15M), cron expression, duration (15m) or seconds", value)
+	}
+	return "R/" + duration, nil
+}
+
+func isIsoCycle(value string) bool {
+	return strings.HasPrefix(value, "R") && strings.Contains(value, "/")
+}
+
+func isCronExpression(value string) bool {
+	return !isIsoCycle(value) && len(strings.Fields(value)) >= 5
+}
+
+// toIsoCycle validates the iso-8601 cycle and converts local date-times of start and end to RFC3339 in location
+func toIsoCycle(value string, location *time.Location) (string, error) {
+	parts := strings.Split(value, "/")
+	repetitions := strings.TrimPrefix(parts[0], "R")
+	if repetitions != "" {
+		if _, err := strconv.ParseUint(repetitions, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid time cycle %v: invalid repetitions", value)
+		}
+	}
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", fmt.Errorf("invalid time cycle %v", value)
+	}
+	hasDuration := false
+	for i, part := range parts[1:] {
+		if isIsoDuration(part) {
+			hasDuration = true
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, part); err == nil {
+			continue
+		}
+		date, err := toIsoDate(part, location)
+		if err != nil {
+			return "", fmt.Errorf("invalid time cycle %v: %v is neither iso-8601 duration nor date", value, part)
+		}
+		parts[i+1] = date
+	}
+	if !hasDuration {
+		return "", fmt.Errorf("invalid time cycle %v: missing duration", value)
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// toQuartzCron converts unix cron expressions (5 fields) to the quartz format used by camunda (seconds field, '?' day and sunday = 1).
+// quartz expressions (6 or 7 fields) are used as is.
+func toQuartzCron(fields []string) (string, error) {
+	expression := strings.Join(fields, " ")
+	if len(fields) > 7 {
+		return "", fmt.Errorf("invalid cron expression %v: too many fields", expression)
+	}
+	for _, field := range fields {
+		if !cronFieldPattern.MatchString(field) {
+			return "", fmt.Errorf("invalid cron expression %v: invalid field %v", expression, field)
+		}
+	}
+	if len(fields) > 5 {
+		return expression, nil
+	}
+	dayOfMonth, dayOfWeek := fields[2], fields[4]
+	switch {
+	case dayOfWeek == "*" || dayOfWeek == "?":
+		dayOfWeek = "?"
+	case dayOfMonth == "*" || dayOfMonth == "?":
+		dayOfMonth = "?"
+	default:
+		return "", fmt.Errorf("invalid cron expression %v: day of month and day of week can not be combined", expression)
+	}
+	if dayOfWeek != "?" {
+		var err error
+		dayOfWeek, err = toQuartzDayOfWeek(dayOfWeek)
+		if err != nil {
+			return "", fmt.Errorf("invalid cron expression %v: %w", expression, err)
+		}
+	}
+	return strings.Join([]string{"0", fields[0], fields[1], dayOfMonth, fields[3], dayOfWeek}, " "), nil
+}
+
+// toQuartzDayOfWeek converts numeric unix weekdays (0-7, sunday = 0 or 7) to quartz weekdays (1-7, sunday = 1).
+// ranges ending on sunday (7) are split, because quartz does not wrap ranges around the end of the week (5-7 -> 6-7,1).
+func toQuartzDayOfWeek(field string) (string, error) {
+	parseDay := func(day string) (int, error) {
+		value, err := strconv.Atoi(day)
+		if err != nil {
+			return 0, err
+		}
+		if value < 0 || value > 7 {
+			return 0, fmt.Errorf("invalid day of week %v", day)
+		}
+		return value, nil
+	}
+	toQuartz := func(day int) string {
+		return strconv.Itoa(day%7 + 1)
+	}
+	parts := strings.Split(field, ",")
+	for i, part := range parts {
+		rangePart, step := part, ""
+		if index := strings.Index(part, "/"); index >= 0 {
+			rangePart, step = part[:index], part[index:]
+		}
+		if rangePart == "*" {
+			continue
+		}
+		bounds := strings.SplitN(rangePart, "-", 2)
+		start, err := parseDay(bounds[0])
+		if errors.Is(err, strconv.ErrSyntax) {
+			continue //names like MON
+		}
+		if err != nil {
+			return "", err
+		}
+		if len(bounds) == 1 && step == "" {
+			parts[i] = toQuartz(start)
+			continue
+		}
+		end := 7 //a start with step ("1/2") runs to the end of the week
+		if len(bounds) == 2 {
+			end, err = parseDay(bounds[1])
+			if err != nil {
+				return "", err
+			}
+		}
+		if start > end {
+			return "", fmt.Errorf("invalid day of week range %v", part)
+		}
+		switch {
+		case end < 7:
+			parts[i] = toQuartz(start) + "-" + toQuartz(end) + step
+		case step != "":
+			stepSize, err := strconv.Atoi(step[1:])
+			if err != nil || stepSize <= 0 {
+				return "", fmt.Errorf("invalid day of week step %v", part)
+			}
+			days := []string{}
+			for day := start; day <= end; day += stepSize {
+				if !slices.Contains(days, toQuartz(day)) {
+					days = append(days, toQuartz(day))
+				}
+			}
+			parts[i] = strings.Join(days, ",")
+		case start == 0:
+			parts[i] = "1-7"
+		case start == 6:
+			parts[i] = "7,1"
+		case start == 7:
+			parts[i] = "1"
+		default:
+			parts[i] = toQuartz(start) + "-7,1"
+		}
+	}
+	return strings.Join(parts, ","), nil
+}
+
+func toIsoDate(value string, location *time.Location) (string, error) {
+	date, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return date.Format(time.RFC3339), nil
+	}
+	for _, layout := range localDateTimeLayouts {
+		date, err = time.ParseInLocation(layout, value, location)
+		if err == nil {
+			return date.Format(time.RFC3339), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %v: expected RFC3339 (2006-01-02T15:04:05Z) or local date-time (2006-01-02T15:04) with timezone", value)
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	drmodel "github.com/SENERGY-Platform/device-repository/lib/model"
+	"github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/auth"
+)
+
+// validateSelections checks every device selection against the device-repository:
+// the device exists and may be executed by the user, the service belongs to the device-type and the selected path exists in the service.
+// violations are returned as element errors; unavailable device-repository responses as retryable error.
+func (this *ProcessDeployment) validateSelections(token auth.Token, deployment deploymentmodel.Deployment) error {
+	if !this.config.ValidateSelections {
+		return nil
+	}
+	devices := map[string]models.Device{}
+	deviceTypes := map[string]models.DeviceType{}
+	elementErrors := []ElementError{}
+	for _, element := range deployment.Elements {
+		selection := getElementSelection(&element)
+		if selection == nil || selection.SelectedDeviceId == nil {
+			continue
+		}
+		device, ok := devices[*selection.SelectedDeviceId]
+		if !ok {
+			var err error
+			var code int
+			device, err, code = this.deviceRepo.ReadDevice(*selection.SelectedDeviceId, token.Jwt(), drmodel.EXECUTE)
+			switch {
+			case code == http.StatusNotFound:
+				elementErrors = append(elementErrors, ElementError{BpmnId: element.BpmnId, Name: element.Name, Message: fmt.Sprintf("unknown device %v", *selection.SelectedDeviceId)})
+				continue
+			case code == http.StatusForbidden || code == http.StatusUnauthorized:
+				elementErrors = append(elementErrors, ElementError{BpmnId: element.BpmnId, Name: element.Name, Message: fmt.Sprintf("user may not execute device %v", *selection.SelectedDeviceId)})
+				continue
+			case err != nil:
+				return newHttpError(ErrCodeUpstreamFailed, code, err.Error(), fmt.Errorf("unable to read device %v: %w", *selection.SelectedDeviceId, err))
+			}
+			devices[*selection.SelectedDeviceId] = device
+		}
+		if selection.SelectedServiceId == nil {
+			continue
+		}
+		deviceType, ok := deviceTypes[device.DeviceTypeId]
+		if !ok {
+			var err error
+			var code int
+			deviceType, err, code = this.deviceRepo.ReadDeviceType(device.DeviceTypeId, token.Jwt())
+			if err != nil {
+				return newHttpError(ErrCodeUpstreamFailed, code, err.Error(), fmt.Errorf("unable to read device-type %v: %w", device.DeviceTypeId, err))
+			}
+			deviceTypes[device.DeviceTypeId] = deviceType
+		}
+		err := validateSelectedService(element, *selection, deviceType)
+		if err != nil {
+			elementErrors = append(elementErrors, ElementError{BpmnId: element.BpmnId, Name: element.Name, Message: err.Error()})
+		}
+	}
+	return newElementErrors(elementErrors)
+}
+
+func validateSelectedService(element deploymentmodel.Element, selection deploymentmodel.Selection, deviceType models.DeviceType) error {
+	for _, service := range deviceType.Services {
+		if service.Id != *selection.SelectedServiceId {
+			continue
+		}
+		if selection.SelectedPath == nil || selection.SelectedPath.Path == "" {
+			return nil
+		}
+		contents := service.Outputs
+		if element.Task != nil {
+			//task paths may reference inputs (e.g. set functions)
+			contents = append(append([]models.Content{}, service.Inputs...), service.Outputs...)
+		}
+		for _, content := range contents {
+			if contentVariableHasPath(content.ContentVariable, "", selection.SelectedPath.Path) {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown path %v in service %v", selection.SelectedPath.Path, service.Id)
+	}
+	return fmt.Errorf("service %v does not belong to device-type %v", *selection.SelectedServiceId, deviceType.Id)
+}
+
+func contentVariableHasPath(variable models.ContentVariable, parentPath string, path string) bool {
+	current := variable.Name
+	if parentPath != "" {
+		current = parentPath + "." + variable.Name
+	}
+	if current == path {
+		return true
+	}
+	if !strings.HasPrefix(path, current+".") {
+		return false
+	}
+	for _, sub := range variable.SubContentVariables {
+		if contentVariableHasPath(sub, current, path) {
+			return true
+		}
+	}
+	return false
+}
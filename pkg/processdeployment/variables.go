@@ -0,0 +1,207 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processdeployment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+// decodeVariable returns the value of a camunda variable as go value (string, bool, int64, float64, nil, []interface{} or map[string]interface{}).
+// untyped variables (as used by the smart-service-repository) are returned unchanged; numbers of untyped variables stay float64.
+// Json and Object variables are expected to be json serialized.
+func decodeVariable(variable model.CamundaVariable) (interface{}, error) {
+	switch variable.Type {
+	case "":
+		return variable.Value, nil
+	case "Null":
+		return nil, nil
+	case "String":
+		if variable.Value == nil {
+			return "", nil
+		}
+		str, ok := variable.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected String, got %T", variable.Value)
+		}
+		return str, nil
+	case "Boolean":
+		return toBool(variable.Value)
+	case "Integer", "Long", "Short":
+		return toInt(variable.Value)
+	case "Double":
+		return toFloat(variable.Value)
+	case "Json", "Object":
+		str, ok := variable.Value.(string)
+		if !ok {
+			return variable.Value, nil
+		}
+		var result interface{}
+		err := json.Unmarshal([]byte(str), &result)
+		if err != nil {
+			return nil, fmt.Errorf("expected json serialized %v: %w", variable.Type, err)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unsupported variable type %v", variable.Type)
+	}
+}
+
+// getVariable returns the decoded value of the task variable with the given name
+func getVariable(task model.CamundaExternalTask, name string) (value interface{}, exists bool, err error) {
+	variable, exists := task.Variables[name]
+	if !exists {
+		return nil, false, nil
+	}
+	value, err = decodeVariable(variable)
+	if err != nil {
+		return nil, true, fmt.Errorf("unexpected value in %v: %w", name, err)
+	}
+	return value, true, nil
+}
+
+// getStringVariable returns the task variable as string; numbers and booleans are formatted
+func getStringVariable(task model.CamundaExternalTask, name string) (value string, exists bool, err error) {
+	raw, exists, err := getVariable(task, name)
+	if err != nil || !exists {
+		return "", exists, err
+	}
+	value, err = toString(raw)
+	if err != nil {
+		return "", true, fmt.Errorf("unexpected value in %v: %w", name, err)
+	}
+	return value, true, nil
+}
+
+// getBoolVariable returns the task variable as bool; strings are parsed with strconv.ParseBool
+func getBoolVariable(task model.CamundaExternalTask, name string) (value bool, exists bool, err error) {
+	raw, exists, err := getVariable(task, name)
+	if err != nil || !exists {
+		return false, exists, err
+	}
+	value, err = toBool(raw)
+	if err != nil {
+		return false, true, fmt.Errorf("unexpected value in %v: %w", name, err)
+	}
+	return value, true, nil
+}
+
+// getJsonStringVariable returns strings unchanged and all other values json encoded
+func getJsonStringVariable(task model.CamundaExternalTask, name string) (value string, exists bool, err error) {
+	raw, exists, err := getVariable(task, name)
+	if err != nil || !exists {
+		return "", exists, err
+	}
+	if str, ok := raw.(string); ok {
+		return str, true, nil
+	}
+	temp, err := json.Marshal(raw)
+	if err != nil {
+		return "", true, fmt.Errorf("unexpected value in %v: %w", name, err)
+	}
+	return string(temp), true, nil
+}
+
+// getJsonVariable unmarshals the task variable into result. json strings and already decoded values (e.g. of Json variables) are accepted.
+func getJsonVariable(task model.CamundaExternalTask, name string, result interface{}) (exists bool, err error) {
+	raw, exists, err := getVariable(task, name)
+	if err != nil || !exists {
+		return exists, err
+	}
+	var temp []byte
+	if str, ok := raw.(string); ok {
+		temp = []byte(str)
+	} else {
+		temp, err = json.Marshal(raw)
+		if err != nil {
+			return true, fmt.Errorf("unexpected value in %v: %w", name, err)
+		}
+	}
+	err = json.Unmarshal(temp, result)
+	if err != nil {
+		return true, fmt.Errorf("unexpected value in %v: %w", name, err)
+	}
+	return true, nil
+}
+
+func toString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("expected string, got %T", value)
+	}
+}
+
+func toBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	default:
+		return false, fmt.Errorf("expected boolean, got %T", value)
+	}
+}
+
+func toInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("expected integer, got %v", v)
+		}
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case json.Number:
+		return v.Int64()
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", value)
+	}
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case json.Number:
+		return v.Float64()
+	default:
+		return 0, errors.New("expected number")
+	}
+}
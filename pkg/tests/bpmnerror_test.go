@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
+)
+
+func TestBpmnErrorOnFailureKeepsProcessInstance(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, _, _, _, camunda, smartServiceRepo, err := prepareMocks(ctx, wg, nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	camunda.AddToQueue([]model.CamundaExternalTask{{
+		Id:                "task1",
+		ProcessInstanceId: "process-instance-1",
+		Variables: map[string]model.CamundaVariable{
+			"process_deployment.name":                  {Value: "missing model id"},
+			"process_deployment.bpmn_error_on_failure": {Value: true},
+		},
+	}})
+
+	time.Sleep(1 * time.Second)
+
+	bpmnErrorThrown := false
+	for _, request := range camunda.PopRequestLog() {
+		switch {
+		case request.Method == "DELETE":
+			t.Error("process instance has been stopped:", request.Endpoint)
+		case request.Endpoint == "/engine-rest/external-task/task1/bpmnError":
+			bpmnErrorThrown = true
+			body := map[string]interface{}{}
+			err = json.Unmarshal([]byte(request.Message), &body)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if body["errorCode"] != "invalid_input" {
+				t.Error("unexpected error code:", body["errorCode"])
+			}
+		}
+	}
+	if !bpmnErrorThrown {
+		t.Error("missing bpmn error")
+	}
+	for _, request := range smartServiceRepo.PopRequestLog() {
+		if request.Endpoint == "/instances-by-process-id/process-instance-1/error" {
+			t.Error("unexpected instance error:", request.Message)
+		}
+	}
+}
+
+func TestFailureWithoutBpmnErrorStopsProcessInstance(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, _, _, _, camunda, smartServiceRepo, err := prepareMocks(ctx, wg, nil, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	camunda.AddToQueue([]model.CamundaExternalTask{{
+		Id:                "task1",
+		ProcessInstanceId: "process-instance-1",
+		Variables: map[string]model.CamundaVariable{
+			"process_deployment.name": {Value: "missing model id"},
+		},
+	}})
+
+	time.Sleep(1 * time.Second)
+
+	stopped := false
+	for _, request := range camunda.PopRequestLog() {
+		switch {
+		case request.Method == "DELETE" && request.Endpoint == "/engine-rest/process-instance/process-instance-1":
+			stopped = true
+		case request.Endpoint == "/engine-rest/external-task/task1/bpmnError":
+			t.Error("unexpected bpmn error:", request.Message)
+		}
+	}
+	if !stopped {
+		t.Error("process instance has not been stopped")
+	}
+	instanceError := false
+	for _, request := range smartServiceRepo.PopRequestLog() {
+		if request.Endpoint == "/instances-by-process-id/process-instance-1/error" {
+			instanceError = true
+		}
+	}
+	if !instanceError {
+		t.Error("missing instance error")
+	}
+}
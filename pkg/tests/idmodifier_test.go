@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/smart-service-module-worker-process/pkg/processdeployment/idmodifier"
+)
+
+func TestIdModifier(t *testing.T) {
+	t.Run("split pure id", func(t *testing.T) {
+		pureId, modifier, err := idmodifier.SplitModifier("device_1")
+		if err != nil || pureId != "device_1" || modifier != nil {
+			t.Error(pureId, modifier, err)
+		}
+	})
+	t.Run("split and join", func(t *testing.T) {
+		pureId, modifier, err := idmodifier.SplitModifier("device_1$service_group_selection=sg1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if pureId != "device_1" || !reflect.DeepEqual(modifier, map[string][]string{idmodifier.ServiceGroupSelectionModifier: {"sg1"}}) {
+			t.Error(pureId, modifier)
+		}
+		id, err := idmodifier.JoinModifier(pureId, modifier)
+		if err != nil || id != "device_1$service_group_selection=sg1" {
+			t.Error(id, err)
+		}
+	})
+	t.Run("join without modifier", func(t *testing.T) {
+		id, err := idmodifier.JoinModifier("device_1", nil)
+		if err != nil || id != "device_1" {
+			t.Error(id, err)
+		}
+	})
+	t.Run("join modified id", func(t *testing.T) {
+		_, err := idmodifier.JoinModifier("device_1$service_group_selection=sg1", map[string][]string{idmodifier.ServiceGroupSelectionModifier: {"sg2"}})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+	t.Run("unknown modifier", func(t *testing.T) {
+		_, _, err := idmodifier.SplitModifier("device_1$foo=bar")
+		if err == nil {
+			t.Error("expected error")
+		}
+		_, err = idmodifier.JoinModifier("device_1", map[string][]string{"foo": {"bar"}})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+	t.Run("invalid modifier", func(t *testing.T) {
+		_, _, err := idmodifier.SplitModifier("device_1$service_group_selection=%zz")
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+	t.Run("pure id of unknown modifier", func(t *testing.T) {
+		for _, id := range []string{"device_1", "device_1$foo=bar", "device_1$service_group_selection=%zz"} {
+			if pureId := idmodifier.PureId(id); pureId != "device_1" {
+				t.Error(id, pureId)
+			}
+		}
+	})
+	t.Run("normalize", func(t *testing.T) {
+		id, err := idmodifier.NormalizeId("device_1$service_group_selection=sg%201")
+		if err != nil || id != "device_1$service_group_selection=sg+1" {
+			t.Error(id, err)
+		}
+	})
+}
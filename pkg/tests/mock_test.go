@@ -17,6 +17,7 @@
 package tests
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"github.com/SENERGY-Platform/process-deployment/lib/model/deploymentmodel"
@@ -50,7 +51,7 @@ func TestWithMocks(t *testing.T) {
 	}
 }
 
-func prepareMocks(ctx context.Context, wg *sync.WaitGroup, responses []mocks.Response) (libConf configuration.Config, conf processdeployment.Config, deployment *mocks.DeploymentMock, fog *mocks.FogDeploymentMock, camunda *mocks.CamundaMock, smartServiceRepo *mocks.SmartServiceRepoMock, err error) {
+func prepareMocks(ctx context.Context, wg *sync.WaitGroup, responses []mocks.Response, configOverwrite []byte) (libConf configuration.Config, conf processdeployment.Config, deployment *mocks.DeploymentMock, fog *mocks.FogDeploymentMock, camunda *mocks.CamundaMock, smartServiceRepo *mocks.SmartServiceRepoMock, err error) {
 	libConf, err = configuration.LoadLibConfig("../../config.json")
 	if err != nil {
 		return
@@ -60,6 +61,15 @@ func prepareMocks(ctx context.Context, wg *sync.WaitGroup, responses []mocks.Res
 		return
 	}
 	conf.AllowMsgEventsInFogProcesses = true
+	conf.DoneEventBackoff = "1h" //prevent done events in test cases without explicit done-event config
+	conf.EventOutboxFile = ""
+	conf.ValidateSelections = false //most test cases have no device-repository responses
+	if configOverwrite != nil {
+		err = json.Unmarshal(configOverwrite, &conf)
+		if err != nil {
+			return
+		}
+	}
 	libConf.CamundaWorkerWaitDurationInMs = 200
 
 	deployment = mocks.NewDeploymentMock()
@@ -79,6 +89,7 @@ func prepareMocks(ctx context.Context, wg *sync.WaitGroup, responses []mocks.Res
 	responsesUrl := mocks.MockResponses(ctx, wg, responses)
 	conf.DeviceRepositoryUrl = responsesUrl
 	conf.FogProcessSyncUrl = responsesUrl
+	conf.FlowRepoUrl = responsesUrl
 
 	err = pkg.Start(ctx, wg, conf, libConf)
 
@@ -128,7 +139,12 @@ func mockTest(t *testing.T, name string) {
 		}
 	}
 
-	_, _, depl, fog, camunda, repo, err := prepareMocks(ctx, wg, responses)
+	configOverwrite, err := os.ReadFile(RESOURCE_BASE_DIR + name + "/config.json")
+	if err != nil {
+		configOverwrite = nil
+	}
+
+	_, conf, depl, fog, camunda, repo, err := prepareMocks(ctx, wg, responses, configOverwrite)
 	if err != nil {
 		t.Error(err)
 		return
@@ -147,6 +163,45 @@ func mockTest(t *testing.T, name string) {
 	}
 	depl.SetPreparedDeployments(preparedDepl)
 
+	deploymentsFile, err := os.ReadFile(RESOURCE_BASE_DIR + name + "/deployments.json")
+	if err == nil {
+		var deployments map[string]deploymentmodel.Deployment
+		err = json.Unmarshal(deploymentsFile, &deployments)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		depl.SetDeployments(deployments)
+	}
+
+	existingModulesFile, err := os.ReadFile(RESOURCE_BASE_DIR + name + "/existing_modules.json")
+	if err == nil {
+		//delete infos of existing modules point to the deployment mock
+		existingModulesFile = bytes.ReplaceAll(existingModulesFile, []byte("http://localhost"), []byte(conf.ProcessDeploymentUrl))
+		var existingModules map[string][]model.SmartServiceModule
+		err = json.Unmarshal(existingModulesFile, &existingModules)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for processInstanceId, modules := range existingModules {
+			repo.SetModules(processInstanceId, modules)
+		}
+	}
+
+	variablesMapFile, err := os.ReadFile(RESOURCE_BASE_DIR + name + "/variables_map.json")
+	if err == nil {
+		var variablesMap map[string]map[string]interface{}
+		err = json.Unmarshal(variablesMapFile, &variablesMap)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for processInstanceId, variables := range variablesMap {
+			repo.SetVariablesMap(processInstanceId, variables)
+		}
+	}
+
 	expectedCamundaRequestsFile, err := os.ReadFile(RESOURCE_BASE_DIR + name + "/expected_camunda_requests.json")
 	if err != nil {
 		t.Error(err)
@@ -44,6 +44,7 @@ type Request struct {
 	Method   string `json:"method"`
 	Endpoint string `json:"endpoint"`
 	Message  string `json:"message"`
+	Status   int    `json:"status,omitempty"`
 }
 
 type Response = Request
@@ -115,6 +116,36 @@ func (this *CamundaMock) getRouter() http.Handler {
 		writer.WriteHeader(200)
 	})
 
+	router.POST("/engine-rest/external-task/:taskId/failure", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		temp, _ := io.ReadAll(request.Body)
+		this.logRequest(Request{
+			Method:   request.Method,
+			Endpoint: request.URL.Path,
+			Message:  string(temp),
+		})
+		writer.WriteHeader(204)
+	})
+
+	router.POST("/engine-rest/external-task/:taskId/bpmnError", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		temp, _ := io.ReadAll(request.Body)
+		this.logRequest(Request{
+			Method:   request.Method,
+			Endpoint: request.URL.Path,
+			Message:  string(temp),
+		})
+		writer.WriteHeader(204)
+	})
+
+	router.POST("/engine-rest/message", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		temp, _ := io.ReadAll(request.Body)
+		this.logRequest(Request{
+			Method:   request.Method,
+			Endpoint: request.URL.Path,
+			Message:  string(temp),
+		})
+		writer.WriteHeader(204)
+	})
+
 	router.DELETE("/engine-rest/process-instance/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 		temp, _ := io.ReadAll(request.Body)
 		this.logRequest(Request{
@@ -38,9 +38,32 @@ func NewDeploymentMock() *DeploymentMock {
 type DeploymentMock struct {
 	requestsLog         []Request
 	preparedDeployments map[string]deploymentmodel.Deployment
+	deployments         map[string]deploymentmodel.Deployment
 	mux                 sync.Mutex
 }
 
+func (this *DeploymentMock) SetDeployments(value map[string]deploymentmodel.Deployment) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.deployments = value
+}
+
+func (this *DeploymentMock) storeDeployment(deployment deploymentmodel.Deployment) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.deployments == nil {
+		this.deployments = map[string]deploymentmodel.Deployment{}
+	}
+	this.deployments[deployment.Id] = deployment
+}
+
+func (this *DeploymentMock) getDeployment(id string) (result deploymentmodel.Deployment, ok bool) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result, ok = this.deployments[id]
+	return
+}
+
 func (this *DeploymentMock) SetPreparedDeployments(value map[string]deploymentmodel.Deployment) {
 	this.mux.Lock()
 	defer this.mux.Unlock()
@@ -89,7 +112,6 @@ func (this *DeploymentMock) getRouter() http.Handler {
 			Endpoint: request.URL.Path,
 			Message:  string(temp),
 		})
-		writer.WriteHeader(200)
 		result, ok := this.getPreparedDeployment(params.ByName("id"))
 		if !ok {
 			http.Error(writer, "unknown model id", http.StatusNotFound)
@@ -128,6 +150,47 @@ func (this *DeploymentMock) getRouter() http.Handler {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
+		this.storeDeployment(deployment)
+		json.NewEncoder(writer).Encode(deployment)
+	})
+
+	router.GET("/v3/deployments/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		temp, _ := io.ReadAll(request.Body)
+		this.logRequest(Request{
+			Method:   request.Method,
+			Endpoint: request.URL.Path,
+			Message:  string(temp),
+		})
+		result, ok := this.getDeployment(params.ByName("id"))
+		if !ok {
+			http.Error(writer, "unknown deployment id", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(writer).Encode(result)
+	})
+
+	router.PUT("/v3/deployments/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		temp, _ := io.ReadAll(request.Body)
+		this.logRequest(Request{
+			Method:   request.Method,
+			Endpoint: request.URL.Path,
+			Message:  string(temp),
+		})
+		deployment := deploymentmodel.Deployment{}
+		err := json.Unmarshal(temp, &deployment)
+		if err != nil {
+			log.Println("ERROR: unable to parse request", err)
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if deployment.Id != params.ByName("id") {
+			http.Error(writer, "deployment id mismatch", http.StatusBadRequest)
+			return
+		}
+		if _, ok := this.getDeployment(deployment.Id); !ok {
+			http.Error(writer, "unknown deployment id", http.StatusNotFound)
+			return
+		}
 		json.NewEncoder(writer).Encode(deployment)
 	})
 
@@ -29,18 +29,25 @@ func MockResponses(ctx context.Context, wg *sync.WaitGroup, responses []Response
 
 	for _, resp := range responses {
 		msg := resp.Message
+		status := resp.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
 		if resp.Method == "GET" {
 			router.GET(resp.Endpoint, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+				writer.WriteHeader(status)
 				writer.Write([]byte(msg))
 			})
 		}
 		if resp.Method == "POST" {
 			router.POST(resp.Endpoint, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+				writer.WriteHeader(status)
 				writer.Write([]byte(msg))
 			})
 		}
 		if resp.Method == "PUT" {
 			router.POST(resp.Endpoint, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+				writer.WriteHeader(status)
 				writer.Write([]byte(msg))
 			})
 		}
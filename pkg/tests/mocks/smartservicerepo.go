@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"github.com/SENERGY-Platform/service-commons/pkg/accesslog"
 	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/configuration"
+	"github.com/SENERGY-Platform/smart-service-module-worker-lib/pkg/model"
 	"github.com/SENERGY-Platform/smart-service-module-worker-process/pkg/processdeployment"
 	"github.com/julienschmidt/httprouter"
 	"io"
@@ -31,7 +32,7 @@ import (
 )
 
 func NewSmartServiceRepoMock(libConfig configuration.Config, config processdeployment.Config) *SmartServiceRepoMock {
-	return &SmartServiceRepoMock{libConfig: libConfig, config: config}
+	return &SmartServiceRepoMock{libConfig: libConfig, config: config, modules: map[string][]model.SmartServiceModule{}, variables: map[string]map[string]interface{}{}}
 }
 
 type SmartServiceRepoMock struct {
@@ -39,6 +40,8 @@ type SmartServiceRepoMock struct {
 	mux         sync.Mutex
 	libConfig   configuration.Config
 	config      processdeployment.Config
+	modules     map[string][]model.SmartServiceModule
+	variables   map[string]map[string]interface{}
 }
 
 func (this *SmartServiceRepoMock) PopRequestLog() []Request {
@@ -55,6 +58,47 @@ func (this *SmartServiceRepoMock) logRequest(r Request) {
 	this.requestsLog = append(this.requestsLog, r)
 }
 
+func (this *SmartServiceRepoMock) SetModules(processInstanceId string, modules []model.SmartServiceModule) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.modules[processInstanceId] = modules
+}
+
+func (this *SmartServiceRepoMock) SetVariablesMap(processInstanceId string, variables map[string]interface{}) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.variables[processInstanceId] = variables
+}
+
+func (this *SmartServiceRepoMock) getVariablesMap(processInstanceId string) map[string]interface{} {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result := this.variables[processInstanceId]
+	if result == nil {
+		result = map[string]interface{}{}
+	}
+	return result
+}
+
+func (this *SmartServiceRepoMock) storeModule(processInstanceId string, module model.SmartServiceModule) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	for i, existing := range this.modules[processInstanceId] {
+		if existing.Id == module.Id {
+			this.modules[processInstanceId][i] = module
+			return
+		}
+	}
+	this.modules[processInstanceId] = append(this.modules[processInstanceId], module)
+}
+
+func (this *SmartServiceRepoMock) listModules(processInstanceId string) (result []model.SmartServiceModule) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result = append([]model.SmartServiceModule{}, this.modules[processInstanceId]...)
+	return result
+}
+
 func (this *SmartServiceRepoMock) Start(ctx context.Context, wg *sync.WaitGroup) (url string) {
 	server := httptest.NewServer(this.getRouter())
 	wg.Add(1)
@@ -88,7 +132,26 @@ func (this *SmartServiceRepoMock) getRouter() http.Handler {
 			Endpoint: request.URL.Path,
 			Message:  msg,
 		})
-		writer.Write(temp)
+		module := model.SmartServiceModule{}
+		err := json.Unmarshal(temp, &module.SmartServiceModuleInit)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		module.Id = params.ByName("moduleId")
+		module.UserId = userId
+		this.storeModule(params.ByName("id"), module)
+		json.NewEncoder(writer).Encode(module)
+	})
+
+	router.GET("/instances-by-process-id/:id/modules", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		temp, _ := io.ReadAll(request.Body)
+		this.logRequest(Request{
+			Method:   request.Method,
+			Endpoint: request.URL.Path,
+			Message:  string(temp),
+		})
+		json.NewEncoder(writer).Encode(this.listModules(params.ByName("id")))
 	})
 
 	router.GET("/instances-by-process-id/:id/user-id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
@@ -108,7 +171,7 @@ func (this *SmartServiceRepoMock) getRouter() http.Handler {
 			Endpoint: request.URL.Path,
 			Message:  string(temp),
 		})
-		writer.Write([]byte(`{}`))
+		json.NewEncoder(writer).Encode(this.getVariablesMap(params.ByName("id")))
 	})
 
 	router.PUT("/instances-by-process-id/:id/variables-map", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEventOutboxRestart(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	outboxFile := filepath.Join(t.TempDir(), "event_outbox.json")
+	past := time.Now().Add(-time.Minute)
+	err := os.WriteFile(outboxFile, []byte(`[
+		{"deployment_id":"sent-before-restart","event_id":"deployment_done_sent-before-restart","next_attempt":"`+past.Format(time.RFC3339)+`"},
+		{"deployment_id":"unknown-deployment","user_id":"user","ready_deadline":"`+past.Format(time.RFC3339)+`","next_attempt":"`+past.Format(time.RFC3339)+`"}
+	]`), 0644)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	configOverwrite, _ := json.Marshal(map[string]string{"event_outbox_file": outboxFile, "done_event_backoff": "100ms"})
+	_, _, _, _, camunda, _, err := prepareMocks(ctx, wg, nil, configOverwrite)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	time.Sleep(1 * time.Second)
+
+	events := []string{}
+	for _, request := range camunda.PopRequestLog() {
+		if request.Endpoint != "/engine-rest/message" {
+			continue
+		}
+		msg := struct {
+			MessageName string `json:"messageName"`
+		}{}
+		err = json.Unmarshal([]byte(request.Message), &msg)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		events = append(events, msg.MessageName)
+	}
+	sort.Strings(events)
+	expected := []string{"deployment_done_sent-before-restart", "deployment_failed_unknown-deployment"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Error(events)
+	}
+
+	content, err := os.ReadFile(outboxFile)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(content) != "[]" {
+		t.Error(string(content))
+	}
+}